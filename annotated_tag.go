@@ -0,0 +1,45 @@
+package tags
+
+import (
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// AnnotatedTag is a [Tag] with annotations (key-value metadata, e.g. a source
+// or a confidence) attached to its values.
+type AnnotatedTag struct {
+	Tag
+	annotations map[string]map[string]string
+}
+
+// Annotate sets the annotation with the key to the val for the value.
+//
+// If the tag doesn't have the value, nothing is annotated.
+func (t *AnnotatedTag) Annotate(value string, key, val string) {
+	if !slices.Contains(t.values, value) {
+		return
+	}
+
+	if t.annotations == nil {
+		t.annotations = make(map[string]map[string]string)
+	}
+	if t.annotations[value] == nil {
+		t.annotations[value] = make(map[string]string)
+	}
+	t.annotations[value][key] = val
+}
+
+// Annotations returns a copy of the annotations of the value. If the value has
+// no annotations, it returns an empty map.
+func (t *AnnotatedTag) Annotations(value string) map[string]string {
+	annotations := maps.Clone(t.annotations[value])
+	if annotations == nil {
+		return map[string]string{}
+	}
+	return annotations
+}
+
+// NewAnnotated creates an annotated tag from the tag, without annotations.
+func NewAnnotated(t Tag) AnnotatedTag {
+	return AnnotatedTag{Tag: t}
+}
@@ -0,0 +1,37 @@
+package tags
+
+import (
+	"testing"
+
+	"golang.org/x/exp/maps"
+)
+
+func TestAnnotate(t *testing.T) {
+	tag := NewAnnotated(Must(NewMultiValue("env", "prod", "dev")))
+	tag.Annotate("prod", "source", "ci")
+	tag.Annotate("prod", "confidence", "0.9")
+	tag.Annotate("missing", "source", "ci")
+
+	want := map[string]string{"source": "ci", "confidence": "0.9"}
+	if got := tag.Annotations("prod"); !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, value := range []string{"dev", "missing"} {
+		if got := tag.Annotations(value); got == nil || len(got) != 0 {
+			t.Errorf("%s: got %v, want an empty map", value, got)
+		}
+	}
+	if !tag.Equal(Must(NewMultiValue("env", "prod", "dev"))) {
+		t.Errorf("tag changed to %v", tag.Tag)
+	}
+}
+
+func TestAnnotationsCopy(t *testing.T) {
+	tag := NewAnnotated(Must(NewSingleValue("env", "prod")))
+	tag.Annotate("prod", "source", "ci")
+
+	tag.Annotations("prod")["source"] = "changed"
+	if got := tag.Annotations("prod")["source"]; got != "ci" {
+		t.Errorf("got %s, want ci", got)
+	}
+}
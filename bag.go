@@ -0,0 +1,66 @@
+package tags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TagBag is like [Tag] but its values are not made unique, i.e. a value can
+// be repeated.
+type TagBag struct {
+	name   string
+	values []string
+}
+
+// Name returns the bag name.
+func (b TagBag) Name() string {
+	return b.name
+}
+
+// Values returns all values of the bag including the repeated ones.
+//
+// If the bag has no values, it returns an empty slice.
+func (b TagBag) Values() []string {
+	if len(b.values) == 0 {
+		return []string{}
+	}
+	return b.values
+}
+
+// ValueCount returns the number of values of the bag including the repeated
+// ones.
+func (b TagBag) ValueCount() int {
+	return len(b.values)
+}
+
+// String returns a string representation of the bag in the name[:value,...]
+// format, see the [Tag.String] method.
+//
+// This method is the reverse of the [ParseBag] function.
+func (b TagBag) String() string {
+	return Tag{name: b.name, values: b.values}.String()
+}
+
+// ParseBag is like [Parse] but returns a [TagBag], i.e. repeating values are
+// kept. Empty-string values are removed.
+//
+// Example:
+//
+//	Must(ParseBag("multi:value,value")) -> TagBag{name: "multi", values: []string{"value", "value"}}
+func ParseBag(bag string) (TagBag, error) {
+	name, values, found := strings.Cut(bag, nameValueSeparator)
+	if strings.TrimSpace(name) == "" {
+		return TagBag{}, fmt.Errorf("name required")
+	}
+	if !found {
+		return TagBag{name: name}, nil
+	}
+
+	var nonEmpty []string
+	for _, v := range strings.Split(values, valuesSeparator) {
+		if strings.TrimSpace(v) != "" {
+			nonEmpty = append(nonEmpty, v)
+		}
+	}
+	return TagBag{name: name, values: nonEmpty}, nil
+}
@@ -0,0 +1,47 @@
+package tags
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestParseBag(t *testing.T) {
+	tests := []struct {
+		s      string
+		name   string
+		values []string
+	}{
+		{"multi:x,x", "multi", []string{"x", "x"}},
+		{"multi:x,y,x,,x", "multi", []string{"x", "y", "x", "x"}},
+		{"single:x", "single", []string{"x"}},
+		{"label", "label", []string{}},
+	}
+	for _, tt := range tests {
+		bag, err := ParseBag(tt.s)
+		if err != nil {
+			t.Errorf("%s: %v", tt.s, err)
+			continue
+		}
+		if bag.Name() != tt.name || !slices.Equal(bag.Values(), tt.values) {
+			t.Errorf("%s: got %s %q, want %s %q", tt.s, bag.Name(), bag.Values(), tt.name, tt.values)
+		}
+		if bag.ValueCount() != len(tt.values) {
+			t.Errorf("%s: got count %d, want %d", tt.s, bag.ValueCount(), len(tt.values))
+		}
+	}
+
+	if _, err := ParseBag(":x"); err == nil {
+		t.Error("expected an error for an empty name")
+	}
+}
+
+func TestBagStringRoundTrip(t *testing.T) {
+	bag := Must(ParseBag("multi:x,y,x"))
+	if got := Must(ParseBag(bag.String())); !slices.Equal(got.Values(), bag.Values()) {
+		t.Errorf("got %v, want %v", got, bag)
+	}
+	if got := bag.String(); got != "multi:x,y,x" {
+		t.Errorf("got %q, want multi:x,y,x", got)
+	}
+}
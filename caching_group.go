@@ -0,0 +1,125 @@
+package tags
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// CachingGroup wraps a [TagGroup] and caches the results of the FindNames and
+// FindValues methods. The cache is cleared on any change made through
+// the CachingGroup.
+//
+// The wrapped group must not be changed directly, otherwise the cached
+// results become stale. A CachingGroup is not safe for concurrent use.
+type CachingGroup struct {
+	group  TagGroup
+	names  map[string][]Tag
+	values map[string][]Tag
+
+	// findNames and findValues are the uncached finds of the group, they can
+	// be replaced in tests.
+	findNames  func(...string) []Tag
+	findValues func(...string) []Tag
+}
+
+// Name returns the group name.
+func (g *CachingGroup) Name() string {
+	return g.group.Name()
+}
+
+// Tags returns the group tags.
+func (g *CachingGroup) Tags() []Tag {
+	return g.group.Tags()
+}
+
+// FindNames returns tags matching the names, see the [TagGroup.FindNames]
+// method. The result is cached.
+func (g *CachingGroup) FindNames(names ...string) []Tag {
+	return cached(g.names, names, g.findNames)
+}
+
+// FindValues returns tags matching the values, see the [TagGroup.FindValues]
+// method. The result is cached.
+func (g *CachingGroup) FindValues(values ...string) []Tag {
+	return cached(g.values, values, g.findValues)
+}
+
+// Rename renames the group, see the [TagGroup.Rename] method.
+func (g *CachingGroup) Rename(newName string) error {
+	return g.group.Rename(newName)
+}
+
+// Add adds tags to the group and clears the cache, see the [TagGroup.Add]
+// method.
+func (g *CachingGroup) Add(tags ...Tag) {
+	g.group.Add(tags...)
+	g.clear()
+}
+
+// Remove removes the matching tags from the group and clears the cache, see
+// the [TagGroup.Remove] method.
+func (g *CachingGroup) Remove(tags ...Tag) {
+	g.group.Remove(tags...)
+	g.clear()
+}
+
+// RemoveNames removes tags matching the names from the group and clears
+// the cache, see the [TagGroup.RemoveNames] method.
+func (g *CachingGroup) RemoveNames(names ...string) {
+	g.group.RemoveNames(names...)
+	g.clear()
+}
+
+// RemoveValues removes tags matching the values from the group and clears
+// the cache, see the [TagGroup.RemoveValues] method.
+func (g *CachingGroup) RemoveValues(values ...string) {
+	g.group.RemoveValues(values...)
+	g.clear()
+}
+
+// RemoveFunc removes tags matching the fn from the group and clears the cache,
+// see the [TagGroup.RemoveFunc] method.
+func (g *CachingGroup) RemoveFunc(fn MatchFunc) {
+	g.group.RemoveFunc(fn)
+	g.clear()
+}
+
+// clear clears the cache.
+func (g *CachingGroup) clear() {
+	g.names = map[string][]Tag{}
+	g.values = map[string][]Tag{}
+}
+
+// cached returns a copy of the result cached for the args, calling the find
+// and caching its result first if there is none.
+func cached(cache map[string][]Tag, args []string, find func(...string) []Tag) []Tag {
+	key := cacheKey(args)
+	result, ok := cache[key]
+	if !ok {
+		result = find(args...)
+		cache[key] = result
+	}
+	return slices.Clone(result)
+}
+
+// cacheKey returns a key unique for the args, i.e. no other args (e.g. with
+// one more empty string or with the same characters split differently) have
+// the same key.
+func cacheKey(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, strconv.Quote(arg))
+	}
+	return strings.Join(quoted, ",")
+}
+
+// NewCachingGroup creates a caching group wrapping the group.
+func NewCachingGroup(g TagGroup) *CachingGroup {
+	group := &CachingGroup{group: g}
+	group.findNames = group.group.FindNames
+	group.findValues = group.group.FindValues
+	group.clear()
+	return group
+}
@@ -0,0 +1,98 @@
+package tags
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+// newCountingGroup returns a caching group counting the calls of
+// the uncached finds.
+func newCountingGroup(t *testing.T, tags ...string) (*CachingGroup, *int) {
+	t.Helper()
+	group := NewCachingGroup(Must(ParseGroupTags("group", tags...)))
+
+	finds := 0
+	findNames, findValues := group.findNames, group.findValues
+	group.findNames = func(names ...string) []Tag {
+		finds++
+		return findNames(names...)
+	}
+	group.findValues = func(values ...string) []Tag {
+		finds++
+		return findValues(values...)
+	}
+	return group, &finds
+}
+
+func tagNames(tags []Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name())
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestCachingGroupCache(t *testing.T) {
+	group, finds := newCountingGroup(t, "label", "single:value", "multi:a,b")
+
+	first := group.FindValues("a", "value")
+	second := group.FindValues("a", "value")
+	if *finds != 1 {
+		t.Errorf("got %d finds, want 1", *finds)
+	}
+	if got, want := tagNames(second), []string{"multi", "single"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !slices.EqualFunc(first, second, Tag.Equal) {
+		t.Errorf("got %v, want %v", second, first)
+	}
+
+	group.FindNames("label")
+	group.FindNames("label")
+	if *finds != 2 {
+		t.Errorf("got %d finds, want 2", *finds)
+	}
+}
+
+func TestCachingGroupMutation(t *testing.T) {
+	group, finds := newCountingGroup(t, "label", "single:value")
+
+	mutations := []struct {
+		name string
+		fn   func()
+	}{
+		{"Add", func() { group.Add(Must(Parse("other"))) }},
+		{"Remove", func() { group.Remove(Must(Parse("other"))) }},
+		{"RemoveNames", func() { group.RemoveNames("other") }},
+		{"RemoveValues", func() { group.RemoveValues("other") }},
+		{"RemoveFunc", func() { group.RemoveFunc(func(Tag) bool { return false }) }},
+	}
+	for _, m := range mutations {
+		group.FindNames("label", "other")
+		before := *finds
+		m.fn()
+		group.FindNames("label", "other")
+		if *finds != before+1 {
+			t.Errorf("%s: the cache was not cleared", m.name)
+		}
+	}
+
+	group.Add(Must(Parse("other")))
+	if got, want := tagNames(group.FindNames("label", "other")), []string{"label", "other"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCachingGroupKeys(t *testing.T) {
+	group, finds := newCountingGroup(t, "label")
+
+	queries := [][]string{{}, {""}, {"", ""}, {"a,b"}, {"a", "b"}, {"a\x00b"}, {`"a"`}}
+	for _, q := range queries {
+		group.FindNames(q...)
+	}
+	if *finds != len(queries) {
+		t.Errorf("got %d finds, want %d", *finds, len(queries))
+	}
+}
@@ -1,12 +1,14 @@
 package tags
 
+import "golang.org/x/exp/slices"
+
 // MustConstraint is a type constraint for the [Must] function.
 type MustConstraint interface {
-	Tag | []Tag | TagGroup | []TagGroup
+	Tag | []Tag | TagGroup | []TagGroup | TagBag
 }
 
-// Must takes a value of [Tag], [][Tag], [TagGroup] or [][TagGroup] and an error
-// and either panics (if error != nil) or returns the value.
+// Must takes a value of [Tag], [][Tag], [TagGroup], [][TagGroup] or [TagBag]
+// and an error and either panics (if error != nil) or returns the value.
 func Must[T MustConstraint](t T, err error) T {
 	if err != nil {
 		panic(err)
@@ -19,3 +21,22 @@ type MatchFunc func(Tag) bool
 
 // LessFunc is used to sort tags by the *Func methods.
 type LessFunc func(Tag, Tag) bool
+
+// MergeFunc is used to merge an existing tag (old) with a new tag with the same
+// name by the [TagGroup.AddWith] method.
+type MergeFunc func(old, new Tag) Tag
+
+// TransformFunc is used to transform groups by the [TagGroup.Apply] method.
+type TransformFunc func(TagGroup) TagGroup
+
+// ContainsTag returns true if the tags contain the t (see the [Tag.Equal]
+// method).
+func ContainsTag(tags []Tag, t Tag) bool {
+	return IndexTag(tags, t) != -1
+}
+
+// IndexTag returns the index of the first occurrence of the t in the tags
+// (see the [Tag.Equal] method), or -1 if not present.
+func IndexTag(tags []Tag, t Tag) int {
+	return slices.IndexFunc(tags, t.Equal)
+}
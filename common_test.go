@@ -0,0 +1,29 @@
+package tags
+
+import "testing"
+
+func TestIndexTag(t *testing.T) {
+	tags := []Tag{
+		Must(NewLabel("label")),
+		{name: "multi", values: []string{"a", "b", "c"}},
+		Must(NewSingleValue("single", "value")),
+	}
+	tests := []struct {
+		tag  Tag
+		want int
+	}{
+		{Must(NewLabel("label")), 0},
+		{Tag{name: "multi", values: []string{"c", "a", "b"}}, 1},
+		{Must(NewSingleValue("single", "value")), 2},
+		{Must(NewSingleValue("single", "other")), -1},
+		{Must(NewLabel("missing")), -1},
+	}
+	for _, tt := range tests {
+		if got := IndexTag(tags, tt.tag); got != tt.want {
+			t.Errorf("IndexTag(%v): got %d, want %d", tt.tag, got, tt.want)
+		}
+		if got := ContainsTag(tags, tt.tag); got != (tt.want != -1) {
+			t.Errorf("ContainsTag(%v): got %v, want %v", tt.tag, got, tt.want != -1)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tags
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// CSVImporter imports tags from CSV records. The columns mapped to the tag
+// names and values are configured with the [CSVImporter.NameColumn] and
+// [CSVImporter.ValueColumns] methods.
+//
+// Example (names in the second column, values in the third and fourth):
+//
+//	group, err := NewCSVImporter().NameColumn(1).ValueColumns(2, 3).Import(r)
+type CSVImporter struct {
+	nameColumn   int
+	valueColumns []int
+}
+
+// NameColumn sets the (zero-based) index of the column with the tag names.
+// The default is 0.
+func (i *CSVImporter) NameColumn(column int) *CSVImporter {
+	i.nameColumn = column
+	return i
+}
+
+// ValueColumns sets the (zero-based) indexes of the columns with the tag
+// values. By default there are no value columns, i.e. all tags are labels.
+func (i *CSVImporter) ValueColumns(columns ...int) *CSVImporter {
+	i.valueColumns = columns
+	return i
+}
+
+// Import reads the CSV records from the r and returns an anonymous group (see
+// the [NewAnonymousGroup] function) with a tag for each record.
+//
+// Empty or missing value cells are skipped, so a record without values
+// becomes a label. The values of records with the same name are combined.
+// A record with an empty or missing name cell is an error.
+func (i *CSVImporter) Import(r io.Reader) (TagGroup, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	group := NewAnonymousGroup()
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return TagGroup{}, err
+		}
+
+		if i.nameColumn < 0 || i.nameColumn >= len(record) {
+			return TagGroup{}, fmt.Errorf("row %d: name required", row)
+		}
+
+		var values []string
+		for _, column := range i.valueColumns {
+			if column >= 0 && column < len(record) {
+				values = append(values, record[column])
+			}
+		}
+
+		tag, err := New(record[i.nameColumn], values...)
+		if err != nil {
+			return TagGroup{}, fmt.Errorf("row %d: %w", row, err)
+		}
+		group.AddWith(combineValues, tag)
+	}
+	return group, nil
+}
+
+// NewCSVImporter creates a CSV importer with the default configuration, see
+// the [CSVImporter] docs.
+func NewCSVImporter() *CSVImporter {
+	return &CSVImporter{}
+}
@@ -0,0 +1,50 @@
+package tags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCSVImport(t *testing.T) {
+	csv := "1,env,prod,eu\n2,region,us,\n3,debug\n4,env,dev,eu\n"
+	group, err := NewCSVImporter().NameColumn(1).ValueColumns(2, 3).Import(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := group.Canonical(), "debug\nenv:dev,eu,prod\nregion:us\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if group.Name() != "" {
+		t.Errorf("got name %q, want an anonymous group", group.Name())
+	}
+}
+
+func TestCSVImportDefaults(t *testing.T) {
+	group, err := NewCSVImporter().Import(strings.NewReader("label,ignored\nother\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := group.Canonical(), "label\nother\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCSVImportErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		column int
+		csv    string
+		prefix string
+	}{
+		{"missing name cell", 1, "1,env\n2\n", "row 2:"},
+		{"empty name cell", 1, "1,env\n2,\n", "row 2:"},
+		{"negative column", -1, "env\n", "row 1:"},
+	}
+	for _, tt := range tests {
+		_, err := NewCSVImporter().NameColumn(tt.column).Import(strings.NewReader(tt.csv))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.prefix) {
+			t.Errorf("%s: got %v, want an error starting with %q", tt.name, err, tt.prefix)
+		}
+	}
+}
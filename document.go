@@ -0,0 +1,59 @@
+package tags
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const groupMarker = "@"
+
+// ParseDocument parses groups from a document where each group starts with
+// a header line with the group name prefixed with '@' followed by lines with
+// the group tags (parsed with the [Parse] function).
+//
+// Leading and trailing whitespace is ignored, so the tag lines can be
+// indented. Empty lines are skipped. A tag line before the first header is
+// an error.
+//
+// Example:
+//
+//	@server
+//	  env:prod
+//	  regions:eu,us
+//
+//	@client
+//	  debug
+//
+// The returned errors contain the number of the offending line.
+func ParseDocument(r io.Reader) ([]TagGroup, error) {
+	var groups []TagGroup
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if name, found := strings.CutPrefix(text, groupMarker); found {
+			group, err := NewGroup(name)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			groups = append(groups, group)
+			continue
+		}
+
+		if len(groups) == 0 {
+			return nil, fmt.Errorf("line %d: tag outside of a group: '%s'", line, text)
+		}
+
+		tag, err := Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		groups[len(groups)-1].Add(tag)
+	}
+	return groups, scanner.Err()
+}
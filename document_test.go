@@ -0,0 +1,55 @@
+package tags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDocument(t *testing.T) {
+	document := `
+@server
+  env:prod
+  regions:eu,us
+
+@client
+  debug
+  a:b
+`
+	groups, err := ParseDocument(strings.NewReader(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name, canonical string
+	}{
+		{"server", "env:prod\nregions:eu,us\n"},
+		{"client", "a:b\ndebug\n"},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("got %d groups, want %d", len(groups), len(want))
+	}
+	for i, w := range want {
+		if groups[i].Name() != w.name || groups[i].Canonical() != w.canonical {
+			t.Errorf("got %s %q, want %s %q", groups[i].Name(), groups[i].Canonical(), w.name, w.canonical)
+		}
+	}
+}
+
+func TestParseDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		prefix   string
+	}{
+		{"tag before header", "env:prod\n@server\n", "line 1:"},
+		{"malformed tag", "@server\n  env:prod\n\n  :value\n", "line 4:"},
+		{"empty group name", "@server\n@\n", "line 2:"},
+	}
+	for _, tt := range tests {
+		_, err := ParseDocument(strings.NewReader(tt.document))
+		if err == nil || !strings.HasPrefix(err.Error(), tt.prefix) {
+			t.Errorf("%s: got %v, want an error starting with %q", tt.name, err, tt.prefix)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package tags
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FromEnv creates a group with a generated name from the environment variables
+// starting with the prefix.
+//
+// The prefix is stripped from the variable names and the rest is lowercased
+// and used as the tag name. The variable value is split by commas into the tag
+// values, so an empty variable becomes a label, a variable without commas
+// a single value tag and a variable with commas a multiple value tag.
+//
+// Examples (with the prefix "APP_TAG_"):
+//
+//	APP_TAG_DEBUG= -> "debug"
+//	APP_TAG_env=prod -> "env:prod"
+//	APP_TAG_REGIONS=eu,us -> "regions:eu,us"
+func FromEnv(prefix string) (TagGroup, error) {
+	return fromEnv(prefix, os.Environ())
+}
+
+// fromEnv is like [FromEnv] but reads the variables from the environ (in
+// the "key=value" format) instead of the environment.
+func fromEnv(prefix string, environ []string) (TagGroup, error) {
+	group := NewGroupWithGeneratedName()
+	for _, kv := range environ {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		tag, err := New(strings.ToLower(strings.TrimPrefix(key, prefix)),
+			strings.Split(value, valuesSeparator)...)
+		if err != nil {
+			return TagGroup{}, fmt.Errorf("invalid variable '%s': %w", key, err)
+		}
+		group.Add(tag)
+	}
+	return group, nil
+}
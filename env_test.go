@@ -0,0 +1,39 @@
+package tags
+
+import "testing"
+
+func TestFromEnv(t *testing.T) {
+	environ := []string{
+		"APP_TAG_DEBUG=",
+		"APP_TAG_env=prod",
+		"APP_TAG_REGIONS=eu,us",
+		"APP_OTHER=ignored",
+		"PATH=/usr/bin",
+	}
+
+	group, err := fromEnv("APP_TAG_", environ)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "debug\nenv:prod\nregions:eu,us\n"
+	if got := group.Canonical(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if !group.ContainsLabel("debug") {
+		t.Error("debug is not a label")
+	}
+	if tag, _ := group.Get("env"); !tag.IsSingleValue() {
+		t.Errorf("env is not a single value tag: %v", tag)
+	}
+	if tag, _ := group.Get("regions"); !tag.IsMultiValue() {
+		t.Errorf("regions is not a multiple value tag: %v", tag)
+	}
+}
+
+func TestFromEnvInvalidName(t *testing.T) {
+	_, err := fromEnv("APP_TAG_", []string{"APP_TAG_=value"})
+	if err == nil {
+		t.Error("expected an error for a variable with just the prefix")
+	}
+}
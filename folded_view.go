@@ -0,0 +1,85 @@
+package tags
+
+import (
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// FoldedView is a read-only view of a [TagGroup] whose methods compare names
+// and values case-insensitively (using [strings.EqualFold]). The tags of
+// the group are not changed.
+type FoldedView struct {
+	group *TagGroup
+}
+
+// FoldedView returns a case-insensitive read-only view of the group.
+//
+// The view reflects later changes of the group.
+func (g *TagGroup) FoldedView() FoldedView {
+	return FoldedView{group: g}
+}
+
+// Tags returns the group tags as they are, i.e. with the original case.
+func (v FoldedView) Tags() []Tag {
+	return v.group.Tags()
+}
+
+// Contains returns true if the group contains the tags, see the [Tag.EqualFold]
+// method.
+func (v FoldedView) Contains(tags ...Tag) bool {
+	for _, t := range tags {
+		if !v.group.ContainsFunc(t.EqualFold) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsNames returns true if the group contains tags matching the names.
+func (v FoldedView) ContainsNames(names ...string) bool {
+	for _, name := range names {
+		found := v.group.ContainsFunc(func(tag Tag) bool {
+			return strings.EqualFold(tag.name, name)
+		})
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsValues returns true if the group contains tags matching the values,
+// see the [TagGroup.ContainsValues] method.
+func (v FoldedView) ContainsValues(values ...string) bool {
+	return v.group.ContainsFunc(func(tag Tag) bool {
+		return hasValuesFold(tag, values)
+	})
+}
+
+// FindNames returns tags matching the names sorted by name.
+func (v FoldedView) FindNames(names ...string) []Tag {
+	return v.group.FindFunc(func(tag Tag) bool {
+		return slices.ContainsFunc(names, func(name string) bool {
+			return strings.EqualFold(tag.name, name)
+		})
+	})
+}
+
+// FindValues returns tags matching the values sorted by name, see
+// the [TagGroup.FindValues] method.
+func (v FoldedView) FindValues(values ...string) []Tag {
+	return v.group.FindFunc(func(tag Tag) bool {
+		return hasValuesFold(tag, values)
+	})
+}
+
+// hasValuesFold is like [Tag.HasValues] but compares the values
+// case-insensitively.
+func hasValuesFold(t Tag, values []string) bool {
+	return slices.ContainsFunc(t.values, func(value string) bool {
+		return slices.ContainsFunc(values, func(v string) bool {
+			return strings.EqualFold(value, v)
+		})
+	})
+}
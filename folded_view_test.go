@@ -0,0 +1,47 @@
+package tags
+
+import "testing"
+
+func TestFoldedView(t *testing.T) {
+	group := Must(ParseGroupTags("group", "Env:prod,Dev", "label"))
+	view := group.FoldedView()
+
+	if !view.ContainsValues("PROD") {
+		t.Error("ContainsValues(PROD) is false")
+	}
+	if view.ContainsValues("stage") {
+		t.Error("ContainsValues(stage) is true")
+	}
+	if !view.ContainsNames("env", "LABEL") {
+		t.Error("ContainsNames(env, LABEL) is false")
+	}
+	if !view.Contains(Must(Parse("ENV:dev,PROD"))) {
+		t.Error("Contains(ENV:dev,PROD) is false")
+	}
+	if view.Contains(Must(Parse("env:prod"))) {
+		t.Error("Contains(env:prod) is true")
+	}
+	if got := view.FindNames("ENV"); len(got) != 1 || got[0].Name() != "Env" {
+		t.Errorf("FindNames(ENV): got %v", got)
+	}
+	if got := view.FindValues("dev"); len(got) != 1 || got[0].Name() != "Env" {
+		t.Errorf("FindValues(dev): got %v", got)
+	}
+
+	if got, want := group.Canonical(), "Env:Dev,prod\nlabel\n"; got != want {
+		t.Errorf("group changed to %q", got)
+	}
+	if got := NewAnonymousGroup(view.Tags()...); got.Canonical() != group.Canonical() {
+		t.Errorf("Tags: got %q, want %q", got.Canonical(), group.Canonical())
+	}
+}
+
+func TestFoldedViewReflectsChanges(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod"))
+	view := group.FoldedView()
+	group.Add(Must(Parse("Region:EU")))
+
+	if !view.ContainsValues("eu") {
+		t.Error("view doesn't reflect the added tag")
+	}
+}
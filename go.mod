@@ -1,6 +1,6 @@
 module github.com/mirovarga/tags
 
-go 1.20
+go 1.23
 
 require (
 	github.com/teris-io/shortid v0.0.0-20220617161101-71ec9f2aa569
@@ -0,0 +1,220 @@
+package tags
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// jsonTag is the JSON representation of a tag.
+type jsonTag struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values,omitempty"`
+}
+
+// MarshalJSON encodes the tag as a JSON object with the name and values
+// fields. The values are sorted, so equal tags (see the [Tag.Equal] method)
+// always produce the same JSON. The values field is omitted for labels.
+//
+// Example:
+//
+//	{"name":"multi","values":["value1","value2"]}
+func (t Tag) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonTag{Name: t.name, Values: t.SortedUniqueValues()})
+}
+
+// UnmarshalJSON decodes the tag from a JSON object created by
+// the [Tag.MarshalJSON] method. The values can be in any order.
+//
+// The tag is created with the [New] function so the same rules apply.
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	var jt jsonTag
+	err := json.Unmarshal(data, &jt)
+	if err != nil {
+		return err
+	}
+
+	tag, err := New(jt.Name, jt.Values...)
+	if err != nil {
+		return err
+	}
+
+	*t = tag
+	return nil
+}
+
+// MarshalTags encodes the tags as a JSON array.
+//
+// The tags are sorted (see the [Tag.Compare] method) and their values are
+// sorted too (see the [Tag.MarshalJSON] method), so the same tags always
+// produce the same JSON regardless of their order.
+func MarshalTags(tags []Tag) ([]byte, error) {
+	sorted := slices.Clone(tags)
+	slices.SortFunc(sorted, func(tag1, tag2 Tag) bool {
+		return tag1.Compare(tag2) < 0
+	})
+	return json.Marshal(sorted)
+}
+
+// WriteJSONL writes the group tags sorted by name to the w in the JSON Lines
+// format, i.e. one JSON encoded tag (see the [Tag.MarshalJSON] method) per line.
+func (g *TagGroup) WriteJSONL(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, t := range g.sortedTags() {
+		err := encoder.Encode(t)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadJSONL reads tags in the JSON Lines format from the r. Empty lines are
+// skipped.
+//
+// This function is the reverse of the [TagGroup.WriteJSONL] method.
+func ReadJSONL(r io.Reader) ([]Tag, error) {
+	var tags []Tag
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var t Tag
+		err := json.Unmarshal(scanner.Bytes(), &t)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, scanner.Err()
+}
+
+// MarshalFlatJSON encodes the group tags as a flat JSON object with the tag
+// names as keys. Single values are encoded as strings, multiple values as
+// sorted arrays and labels as null. The group name is not included.
+//
+// Example:
+//
+//	{"label":null,"multi":["value1","value2"],"single":"value"}
+func (g *TagGroup) MarshalFlatJSON() ([]byte, error) {
+	object := make(map[string]any, len(g.tags))
+	for name, t := range g.tags {
+		switch len(t.values) {
+		case 0:
+			object[name] = nil
+		case 1:
+			object[name] = t.values[0]
+		default:
+			object[name] = t.SortedUniqueValues()
+		}
+	}
+	return json.Marshal(object)
+}
+
+// UnmarshalFlatJSON decodes an anonymous group (see the [NewAnonymousGroup]
+// function) from a flat JSON object created by the [TagGroup.MarshalFlatJSON]
+// method.
+//
+// The tags are created with the [New] function so the same rules apply.
+func UnmarshalFlatJSON(data []byte) (TagGroup, error) {
+	var object map[string]json.RawMessage
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return TagGroup{}, err
+	}
+
+	group := NewAnonymousGroup()
+	for name, raw := range object {
+		var values []string
+		if raw[0] == '"' {
+			var value string
+			err = json.Unmarshal(raw, &value)
+			values = []string{value}
+		} else {
+			err = json.Unmarshal(raw, &values)
+		}
+		if err != nil {
+			return TagGroup{}, fmt.Errorf("invalid tag '%s': %w", name, err)
+		}
+
+		tag, err := New(name, values...)
+		if err != nil {
+			return TagGroup{}, err
+		}
+		group.Add(tag)
+	}
+	return group, nil
+}
+
+// jsonGroup is the JSON representation of a group.
+type jsonGroup struct {
+	Name string `json:"name,omitempty"`
+	Tags []Tag  `json:"tags"`
+}
+
+// JSONOptions configures the JSON encoding of groups by
+// the [JSONOptions.Marshal] method.
+type JSONOptions struct {
+	// OmitLabels omits labels (see the [Tag.IsLabel] method) from the encoded
+	// group.
+	OmitLabels bool
+}
+
+// Marshal encodes the group as a JSON object with the name and tags fields
+// using the options. The tags are sorted by name and encoded with
+// the [Tag.MarshalJSON] method. The name field is omitted for anonymous
+// groups.
+//
+// Example:
+//
+//	{"name":"group","tags":[{"name":"label"},{"name":"single","values":["value"]}]}
+func (o JSONOptions) Marshal(g TagGroup) ([]byte, error) {
+	tags := []Tag{}
+	for _, t := range g.sortedTags() {
+		if !o.OmitLabels || !t.IsLabel() {
+			tags = append(tags, t)
+		}
+	}
+	return json.Marshal(jsonGroup{Name: g.name, Tags: tags})
+}
+
+// MarshalJSON encodes the group with the default [JSONOptions], see
+// the [JSONOptions.Marshal] method.
+func (g TagGroup) MarshalJSON() ([]byte, error) {
+	return JSONOptions{}.Marshal(g)
+}
+
+// UnmarshalJSON decodes the group from a JSON object created by
+// the [TagGroup.MarshalJSON] method. A missing name results in an anonymous
+// group, see the [NewAnonymousGroup] function.
+//
+// It returns [ErrFrozen] if the group is frozen, see the [TagGroup.Freeze]
+// method.
+func (g *TagGroup) UnmarshalJSON(data []byte) error {
+	if g.IsFrozen() {
+		return ErrFrozen
+	}
+
+	var jg jsonGroup
+	err := json.Unmarshal(data, &jg)
+	if err != nil {
+		return err
+	}
+
+	group := NewAnonymousGroup(jg.Tags...)
+	if jg.Name != "" {
+		err = group.Rename(jg.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	*g = group
+	return nil
+}
@@ -0,0 +1,234 @@
+package tags
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestMarshalTags(t *testing.T) {
+	tags1 := []Tag{
+		Must(NewMultiValue("multi", "b", "a")),
+		Must(NewLabel("label")),
+		Must(NewSingleValue("single", "value")),
+	}
+	tags2 := []Tag{
+		Must(NewSingleValue("single", "value")),
+		Must(NewMultiValue("multi", "a", "b")),
+		Must(NewLabel("label")),
+	}
+
+	json1, err := MarshalTags(tags1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	json2, err := MarshalTags(tags2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[{"name":"label"},{"name":"multi","values":["a","b"]},{"name":"single","values":["value"]}]`
+	if string(json1) != want {
+		t.Errorf("got %s, want %s", json1, want)
+	}
+	if string(json1) != string(json2) {
+		t.Errorf("%s != %s", json1, json2)
+	}
+}
+
+func TestJSONLRoundTrip(t *testing.T) {
+	group := Must(ParseGroupTags("group", "label", "single:value", "multi:b,a"))
+
+	var buf bytes.Buffer
+	err := group.WriteJSONL(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{
+		`{"name":"label"}`,
+		`{"name":"multi","values":["a","b"]}`,
+		`{"name":"single","values":["value"]}`,
+	}
+	if !slices.Equal(lines, want) {
+		t.Errorf("got %q, want %q", lines, want)
+	}
+	for _, line := range lines {
+		var tag Tag
+		if err := json.Unmarshal([]byte(line), &tag); err != nil {
+			t.Errorf("%s: %v", line, err)
+		}
+	}
+
+	tags, err := ReadJSONL(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := NewAnonymousGroup(tags...); got.Canonical() != group.Canonical() {
+		t.Errorf("got %q, want %q", got.Canonical(), group.Canonical())
+	}
+}
+
+func TestReadJSONLInvalidLine(t *testing.T) {
+	_, err := ReadJSONL(strings.NewReader("{\"name\":\"a\"}\n\n{\"name\":\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("got %v, want an error for line 3", err)
+	}
+}
+
+func TestFlatJSONRoundTrip(t *testing.T) {
+	group := Must(ParseGroupTags("group", "label", "single:value", "multi:b,a"))
+
+	data, err := group.MarshalFlatJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"label":null,"multi":["a","b"],"single":"value"}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	decoded, err := UnmarshalFlatJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Canonical() != group.Canonical() {
+		t.Errorf("got %q, want %q", decoded.Canonical(), group.Canonical())
+	}
+	if decoded.Name() != "" {
+		t.Errorf("got name %q, want an anonymous group", decoded.Name())
+	}
+}
+
+func TestFlatJSONEmptyGroup(t *testing.T) {
+	group := NewAnonymousGroup()
+	data, err := group.MarshalFlatJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "{}" {
+		t.Errorf("got %s, want {}", data)
+	}
+
+	decoded, err := UnmarshalFlatJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Tags()) != 0 {
+		t.Errorf("got %v, want no tags", decoded.Tags())
+	}
+}
+
+func TestUnmarshalFlatJSONErrors(t *testing.T) {
+	for _, data := range []string{`[]`, `{"tag":1}`, `{"":"value"}`, `{"tag":{"a":"b"}}`} {
+		if _, err := UnmarshalFlatJSON([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", data)
+		}
+	}
+}
+
+func TestMarshalGroup(t *testing.T) {
+	group := Must(ParseGroupTags("group", "label", "single:value", "multi:b,a"))
+
+	tests := []struct {
+		name    string
+		options JSONOptions
+		want    string
+	}{
+		{"default", JSONOptions{}, `{"name":"group","tags":[{"name":"label"},{"name":"multi","values":["a","b"]},{"name":"single","values":["value"]}]}`},
+		{"omit labels", JSONOptions{OmitLabels: true}, `{"name":"group","tags":[{"name":"multi","values":["a","b"]},{"name":"single","values":["value"]}]}`},
+	}
+	for _, tt := range tests {
+		data, err := tt.options.Marshal(group)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, data, tt.want)
+		}
+	}
+
+	data, err := json.Marshal(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := tests[0].want; string(data) != want {
+		t.Errorf("MarshalJSON: got %s, want %s", data, want)
+	}
+}
+
+func TestGroupJSONRoundTrip(t *testing.T) {
+	groups := []TagGroup{
+		Must(ParseGroupTags("group", "label", "single:value", "multi:b,a")),
+		NewAnonymousGroup(Must(Parse("label"))),
+	}
+	for _, group := range groups {
+		data, err := json.Marshal(group)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got TagGroup
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Name() != group.Name() || got.Canonical() != group.Canonical() {
+			t.Errorf("got %s %q, want %s %q", got.Name(), got.Canonical(), group.Name(), group.Canonical())
+		}
+	}
+}
+
+func TestUnmarshalFrozenGroup(t *testing.T) {
+	group := Must(ParseGroupTags("group", "label"))
+	group.Freeze()
+
+	err := json.Unmarshal([]byte(`{"name":"other","tags":[{"name":"other"}]}`), &group)
+	if err != ErrFrozen {
+		t.Errorf("got %v, want %v", err, ErrFrozen)
+	}
+	if group.Name() != "group" || group.Canonical() != "label\n" {
+		t.Errorf("frozen group changed to %s %q", group.Name(), group.Canonical())
+	}
+}
+
+func TestMarshalTagStable(t *testing.T) {
+	tags := []Tag{
+		Must(NewMultiValue("multi", "c", "a", "b")),
+		Must(NewMultiValue("multi", "b", "c", "a")),
+		{name: "multi", values: []string{"a", "c", "b", "a"}},
+	}
+
+	want := `{"name":"multi","values":["a","b","c"]}`
+	for _, tag := range tags {
+		for i := 0; i < 10; i++ {
+			data, err := json.Marshal(tag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != want {
+				t.Errorf("got %s, want %s", data, want)
+			}
+		}
+	}
+
+	label, err := json.Marshal(Must(NewLabel("label")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"label"}`; string(label) != want {
+		t.Errorf("got %s, want %s", label, want)
+	}
+}
+
+func TestUnmarshalTagAnyOrder(t *testing.T) {
+	var tag Tag
+	if err := json.Unmarshal([]byte(`{"name":"multi","values":["c","a","b"]}`), &tag); err != nil {
+		t.Fatal(err)
+	}
+	if want := Must(Parse("multi:a,b,c")); !tag.Equal(want) {
+		t.Errorf("got %v, want %v", tag, want)
+	}
+}
@@ -0,0 +1,43 @@
+package tags
+
+import "strings"
+
+// markdownEscaper escapes characters with a special meaning in Markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	">", `\>`,
+	"|", `\|`,
+	"#", `\#`,
+)
+
+// Markdown returns the group tags as a Markdown list sorted by name, with
+// the values sorted too. Labels are rendered without values. Characters with
+// a special meaning in Markdown are escaped.
+//
+// Example:
+//
+//	group.Markdown() -> "- **label**\n- **multi**: value1, value2\n"
+func (g *TagGroup) Markdown() string {
+	var b strings.Builder
+	for _, t := range g.sortedTags() {
+		b.WriteString("- **")
+		b.WriteString(markdownEscaper.Replace(t.name))
+		b.WriteString("**")
+		for i, v := range t.SortedUniqueValues() {
+			if i == 0 {
+				b.WriteString(": ")
+			} else {
+				b.WriteString(", ")
+			}
+			b.WriteString(markdownEscaper.Replace(v))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package tags
+
+import "testing"
+
+func TestMarkdown(t *testing.T) {
+	group := Must(ParseGroupTags("group", "single:value", "multi:value2,value1", "label"))
+
+	want := "- **label**\n- **multi**: value1, value2\n- **single**: value\n"
+	if got := group.Markdown(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownEscaping(t *testing.T) {
+	group := Must(ParseGroupTags("group", "my_tag:*bold*,[link]", "a|b:`code`"))
+
+	want := "- **a\\|b**: \\`code\\`\n- **my\\_tag**: \\*bold\\*, \\[link\\]\n"
+	if got := group.Markdown(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownEmptyGroup(t *testing.T) {
+	group := NewAnonymousGroup()
+	if got := group.Markdown(); got != "" {
+		t.Errorf("got %q, want an empty string", got)
+	}
+}
@@ -0,0 +1,140 @@
+package tags
+
+import "golang.org/x/exp/slices"
+
+// ProvenanceGroup wraps a [TagGroup] and remembers the source of its tags.
+// The group can be changed only through the ProvenanceGroup, so the sources
+// are always up to date.
+//
+// The zero value is an empty anonymous group (see the [NewAnonymousGroup]
+// function) ready to use.
+type ProvenanceGroup struct {
+	group   TagGroup
+	sources map[string]string
+}
+
+// Name returns the group name.
+func (g *ProvenanceGroup) Name() string {
+	return g.group.Name()
+}
+
+// Tags returns the group tags.
+func (g *ProvenanceGroup) Tags() []Tag {
+	return g.group.Tags()
+}
+
+// Get returns the tag with the name, see the [TagGroup.Get] method.
+func (g *ProvenanceGroup) Get(name string) (Tag, bool) {
+	return g.group.Get(name)
+}
+
+// Contains returns true if the group contains the tags, see
+// the [TagGroup.Contains] method.
+func (g *ProvenanceGroup) Contains(tags ...Tag) bool {
+	return g.group.Contains(tags...)
+}
+
+// FindNames returns tags matching the names, see the [TagGroup.FindNames]
+// method.
+func (g *ProvenanceGroup) FindNames(names ...string) []Tag {
+	return g.group.FindNames(names...)
+}
+
+// FindValues returns tags matching the values, see the [TagGroup.FindValues]
+// method.
+func (g *ProvenanceGroup) FindValues(values ...string) []Tag {
+	return g.group.FindValues(values...)
+}
+
+// FindFunc returns tags matching the fn, see the [TagGroup.FindFunc] method.
+func (g *ProvenanceGroup) FindFunc(fn MatchFunc) []Tag {
+	return g.group.FindFunc(fn)
+}
+
+// Group returns a copy of the group without the sources, see
+// the [TagGroup.Clone] method.
+func (g *ProvenanceGroup) Group() TagGroup {
+	return g.group.Clone()
+}
+
+// Rename renames the group, see the [TagGroup.Rename] method.
+func (g *ProvenanceGroup) Rename(newName string) error {
+	return g.group.Rename(newName)
+}
+
+// AddFrom adds tags from the source to the group, see the [TagGroup.Add]
+// method docs. The source of a tag is the source it was last added from.
+func (g *ProvenanceGroup) AddFrom(source string, tags ...Tag) {
+	g.init()
+	g.group.Add(tags...)
+	for _, t := range tags {
+		g.sources[t.name] = source
+	}
+}
+
+// Add adds tags without a source to the group, see the [TagGroup.Add] method
+// docs.
+func (g *ProvenanceGroup) Add(tags ...Tag) {
+	g.init()
+	g.group.Add(tags...)
+	for _, t := range tags {
+		delete(g.sources, t.name)
+	}
+}
+
+// Remove removes the matching tags and their sources from the group, see
+// the [TagGroup.Remove] method.
+func (g *ProvenanceGroup) Remove(tags ...Tag) {
+	g.RemoveFunc(func(tag Tag) bool {
+		return ContainsTag(tags, tag)
+	})
+}
+
+// RemoveNames removes tags matching the names and their sources from
+// the group, see the [TagGroup.RemoveNames] method.
+func (g *ProvenanceGroup) RemoveNames(names ...string) {
+	g.RemoveFunc(func(tag Tag) bool {
+		return slices.Contains(names, tag.name)
+	})
+}
+
+// RemoveFunc removes tags matching the fn and their sources from the group,
+// see the [TagGroup.RemoveFunc] method.
+func (g *ProvenanceGroup) RemoveFunc(fn MatchFunc) {
+	for _, t := range g.group.RemoveFuncReturning(fn) {
+		delete(g.sources, t.name)
+	}
+}
+
+// SourceOf returns the source of the tag with the name and true, or an empty
+// string and false if the group doesn't contain such tag or it was not added
+// with the [ProvenanceGroup.AddFrom] method.
+func (g *ProvenanceGroup) SourceOf(name string) (string, bool) {
+	if _, ok := g.group.tags[name]; !ok {
+		return "", false
+	}
+
+	source, ok := g.sources[name]
+	return source, ok
+}
+
+// init initializes the zero value of the group.
+func (g *ProvenanceGroup) init() {
+	if g.group.tags == nil {
+		g.group = NewAnonymousGroup()
+	}
+	if g.sources == nil {
+		g.sources = map[string]string{}
+	}
+}
+
+// NewProvenanceGroup creates a provenance group with the specified name.
+//
+// The group name cannot be an empty string.
+func NewProvenanceGroup(name string) (ProvenanceGroup, error) {
+	group, err := NewGroup(name)
+	if err != nil {
+		return ProvenanceGroup{}, err
+	}
+	return ProvenanceGroup{group: group, sources: map[string]string{}}, nil
+}
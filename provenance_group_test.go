@@ -0,0 +1,79 @@
+package tags
+
+import "testing"
+
+func newTestProvenanceGroup(t *testing.T, name string) ProvenanceGroup {
+	t.Helper()
+	group, err := NewProvenanceGroup(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return group
+}
+
+func TestProvenanceGroup(t *testing.T) {
+	group := newTestProvenanceGroup(t, "group")
+	group.AddFrom("srcA", Must(Parse("env:prod")), Must(Parse("region:eu")))
+	group.AddFrom("srcB", Must(Parse("env:dev")))
+	group.Add(Must(Parse("label")))
+
+	tests := []struct {
+		name   string
+		source string
+		ok     bool
+	}{
+		{"env", "srcB", true},
+		{"region", "srcA", true},
+		{"label", "", false},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		if source, ok := group.SourceOf(tt.name); source != tt.source || ok != tt.ok {
+			t.Errorf("%s: got %s, %v, want %s, %v", tt.name, source, ok, tt.source, tt.ok)
+		}
+	}
+
+	if got := group.FindValues("dev"); len(got) != 1 || got[0].Name() != "env" {
+		t.Errorf("FindValues: got %v", got)
+	}
+	if !group.Contains(Must(Parse("region:eu"))) {
+		t.Error("Contains: region:eu missing")
+	}
+	copied := group.Group()
+	if got, want := copied.Canonical(), "env:dev\nlabel\nregion:eu\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestProvenanceGroupMutations(t *testing.T) {
+	group := newTestProvenanceGroup(t, "group")
+	group.AddFrom("srcA", Must(Parse("env:prod")), Must(Parse("region:eu")), Must(Parse("label")))
+
+	group.Add(Must(Parse("env:dev")))
+	if source, ok := group.SourceOf("env"); ok {
+		t.Errorf("env: got source %s after Add", source)
+	}
+
+	group.RemoveNames("region")
+	group.Add(Must(Parse("region:us")))
+	if source, ok := group.SourceOf("region"); ok {
+		t.Errorf("region: got source %s after removing and adding", source)
+	}
+
+	group.Remove(Must(Parse("label")))
+	if _, ok := group.Get("label"); ok {
+		t.Error("label not removed")
+	}
+}
+
+func TestProvenanceGroupZeroValue(t *testing.T) {
+	var group ProvenanceGroup
+	group.AddFrom("srcA", Must(Parse("env:prod")))
+
+	if source, ok := group.SourceOf("env"); source != "srcA" || !ok {
+		t.Errorf("got %s, %v, want srcA, true", source, ok)
+	}
+	if group.Name() != "" {
+		t.Errorf("got name %q, want an anonymous group", group.Name())
+	}
+}
@@ -0,0 +1,82 @@
+package tags
+
+import "golang.org/x/exp/slices"
+
+// Query filters the groups of a [TagStore]. The constraints are added with
+// the With* methods and all of them must be satisfied by a group to match.
+// The With* methods return a new query, so a query can be reused as a base for
+// other queries.
+//
+// Example:
+//
+//	store.Query().WithName("env").WithValue("prod").Groups()
+type Query struct {
+	store      *TagStore
+	predicates []func(*TagGroup) bool
+}
+
+// Query returns a query over the store groups. A query without constraints
+// matches all groups.
+func (s *TagStore) Query() *Query {
+	return &Query{store: s}
+}
+
+// WithName returns a copy of the query constrained to groups with a tag with
+// the name.
+func (q *Query) WithName(name string) *Query {
+	return q.WithGroupFunc(func(g *TagGroup) bool {
+		return g.ContainsNames(name)
+	})
+}
+
+// WithValue returns a copy of the query constrained to groups with a tag
+// with the value.
+func (q *Query) WithValue(value string) *Query {
+	return q.WithGroupFunc(func(g *TagGroup) bool {
+		return g.CountValue(value) > 0
+	})
+}
+
+// WithFunc returns a copy of the query constrained to groups with a tag
+// matching the fn.
+func (q *Query) WithFunc(fn MatchFunc) *Query {
+	return q.WithGroupFunc(func(g *TagGroup) bool {
+		return g.ContainsFunc(fn)
+	})
+}
+
+// WithGroupFunc returns a copy of the query constrained to groups matching
+// the fn.
+func (q *Query) WithGroupFunc(fn func(*TagGroup) bool) *Query {
+	predicates := make([]func(*TagGroup) bool, 0, len(q.predicates)+1)
+	predicates = append(predicates, q.predicates...)
+	return &Query{store: q.store, predicates: append(predicates, fn)}
+}
+
+// Groups returns the groups matching the query sorted by name.
+func (q *Query) Groups() []TagGroup {
+	groups := []TagGroup{}
+	for _, g := range q.store.Groups() {
+		if q.matches(&g) {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// Count returns the number of groups matching the query.
+func (q *Query) Count() (count int) {
+	for _, g := range q.store.groups {
+		if q.matches(&g) {
+			count++
+		}
+	}
+	return
+}
+
+// matches returns true if the group matches all the query constraints.
+func (q *Query) matches(g *TagGroup) bool {
+	return !slices.ContainsFunc(q.predicates, func(predicate func(*TagGroup) bool) bool {
+		return !predicate(g)
+	})
+}
@@ -0,0 +1,68 @@
+package tags
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestQuery(t *testing.T) {
+	store := newTestStoreOf(t,
+		Must(ParseGroupTags("a", "env:prod", "region:eu")),
+		Must(ParseGroupTags("b", "env:dev", "region:eu")),
+		Must(ParseGroupTags("c", "env:prod", "owner:alice")),
+		Must(ParseGroupTags("d", "prod", "region:eu")),
+	)
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  []string
+	}{
+		{"empty", store.Query(), []string{"a", "b", "c", "d"}},
+		{"name", store.Query().WithName("env"), []string{"a", "b", "c"}},
+		{"value", store.Query().WithValue("prod"), []string{"a", "c"}},
+		{"name and value", store.Query().WithName("region").WithValue("prod"), []string{"a"}},
+		{"func", store.Query().WithFunc(Tag.IsLabel), []string{"d"}},
+		{"no match", store.Query().WithName("owner").WithValue("dev"), []string{}},
+	}
+	for _, tt := range tests {
+		names := []string{}
+		for _, g := range tt.query.Groups() {
+			names = append(names, g.Name())
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, names, tt.want)
+		}
+		if got := tt.query.Count(); got != len(tt.want) {
+			t.Errorf("%s: got count %d, want %d", tt.name, got, len(tt.want))
+		}
+	}
+}
+
+func TestQueryReuse(t *testing.T) {
+	store := newTestStoreOf(t,
+		Must(ParseGroupTags("a", "env:prod")),
+		Must(ParseGroupTags("b", "env:dev")),
+		Must(ParseGroupTags("c", "region:eu")),
+	)
+
+	base := store.Query().WithName("env")
+	prod := base.WithValue("prod")
+	dev := base.WithValue("dev")
+
+	tests := []struct {
+		name  string
+		query *Query
+		want  int
+	}{
+		{"base", base, 2},
+		{"prod", prod, 1},
+		{"dev", dev, 1},
+	}
+	for _, tt := range tests {
+		if got := tt.query.Count(); got != tt.want {
+			t.Errorf("%s: got %d groups, want %d", tt.name, got, tt.want)
+		}
+	}
+}
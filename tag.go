@@ -2,7 +2,11 @@ package tags
 
 import (
 	"fmt"
+	"iter"
+	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
 
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
@@ -11,14 +15,77 @@ import (
 const (
 	nameValueSeparator = ":"
 	valuesSeparator    = ","
+	labelMarker        = "#"
+	disabledMarker     = "!"
 )
 
+// CaseMode specifies how the [New] function converts the case of tag names
+// and values.
+type CaseMode int
+
+const (
+	// CaseNone keeps the case unchanged.
+	CaseNone CaseMode = iota
+	// CaseLower converts to lower case.
+	CaseLower
+	// CaseUpper converts to upper case.
+	CaseUpper
+)
+
+// Case is the case mode used by the [New] function (and all functions using
+// it). The default is [CaseNone].
+//
+// For example, with [CaseLower] New("Env", "Prod", "prod") creates the tag
+// "env:prod".
+var Case = CaseNone
+
+// apply converts the s according to the mode.
+func (m CaseMode) apply(s string) string {
+	switch m {
+	case CaseLower:
+		return strings.ToLower(s)
+	case CaseUpper:
+		return strings.ToUpper(s)
+	default:
+		return s
+	}
+}
+
+// ValueValidator validates the values of tags created by the [New] function
+// (and all functions using it). If it returns an error for a value, New
+// returns an error too. The default is nil, i.e. all values are valid.
+//
+// Example (values without whitespace):
+//
+//	ValueValidator = RegexpValueValidator(regexp.MustCompile(`^\S+$`))
+var ValueValidator func(string) error
+
+// RegexpValueValidator returns a value validator (see the [ValueValidator]
+// variable) that accepts only values matching the re.
+func RegexpValueValidator(re *regexp.Regexp) func(string) error {
+	return func(value string) error {
+		if !re.MatchString(value) {
+			return fmt.Errorf("must match '%s'", re)
+		}
+		return nil
+	}
+}
+
+// NamespaceSeparator separates a namespace from a tag name, see
+// the [Tag.WithNamespace] method.
+var NamespaceSeparator = "."
+
 // Tag can be a label (a tag without a value), a single value tag (a tag with
 // a name and one value) or a multiple value tag (a tag with a name and more
 // than one value).
+//
+// A label can also be an empty-value tag, i.e. a tag with a name and an empty
+// value, see the [ParseVerbose] function. It's a label for all purposes except
+// the [Tag.IsEmptyValue] and [Tag.StringVerbose] methods.
 type Tag struct {
-	name   string
-	values []string
+	name       string
+	values     []string
+	emptyValue bool
 }
 
 // Name returns the tag name.
@@ -49,17 +116,122 @@ func (t Tag) Values() []string {
 	return t.values
 }
 
+// ValueParts returns the tag values (in the same order as the [Tag.Values]
+// method) each split by the sep. A value without the sep becomes a one-element
+// slice.
+//
+// Example:
+//
+//	Must(Parse("matrix:a;b,c;d")).ValueParts(";") -> [][]string{{"a", "b"}, {"c", "d"}}
+func (t Tag) ValueParts(sep string) [][]string {
+	parts := make([][]string, 0, len(t.values))
+	for _, v := range t.values {
+		parts = append(parts, strings.Split(v, sep))
+	}
+	return parts
+}
+
+// ValueSet returns the tag values as a set.
+//
+// The set is a copy, i.e. changing it doesn't change the tag.
+func (t Tag) ValueSet() map[string]struct{} {
+	set := make(map[string]struct{}, len(t.values))
+	for _, v := range t.values {
+		set[v] = struct{}{}
+	}
+	return set
+}
+
+// ValueMembership returns for each vocab entry whether the tag has it as
+// a value, i.e. the result has the same length as the vocab.
+//
+// It's useful when checking the same vocabulary repeatedly.
+func (t Tag) ValueMembership(vocab []string) []bool {
+	membership := make([]bool, len(vocab))
+	for i, v := range vocab {
+		membership[i] = slices.Contains(t.values, v)
+	}
+	return membership
+}
+
+// Pairs returns an iterator over the (name, value) pairs of the tag, one for
+// each value. A label yields a single pair with an empty value.
+//
+// Example:
+//
+//	for name, value := range tag.Pairs() {
+//		// ...
+//	}
+func (t Tag) Pairs() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		if t.IsLabel() {
+			yield(t.name, "")
+			return
+		}
+
+		for _, v := range t.values {
+			if !yield(t.name, v) {
+				return
+			}
+		}
+	}
+}
+
+// sorted returns a copy of the tag with sorted values.
+func (t Tag) sorted() Tag {
+	t.values = t.SortedUniqueValues()
+	return t
+}
+
+// SortedUniqueValues returns a sorted copy of the tag values without
+// repeating values. Unlike the [Tag.Values] method, the order is always
+// the same, so it's suitable for output or hashing.
+func (t Tag) SortedUniqueValues() []string {
+	values := slices.Clone(t.Values())
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// SortValuesFunc returns a copy of the tag with the values sorted by the less
+// function. The sort is stable.
+//
+// Note that tags created by the [New] function (or other functions using it)
+// don't keep the order of the values.
+func (t Tag) SortValuesFunc(less func(a, b string) bool) Tag {
+	values := slices.Clone(t.values)
+	slices.SortStableFunc(values, less)
+	t.values = values
+	return t
+}
+
+// IsZero returns true if the tag is the zero value, i.e. Tag{}.
+func (t Tag) IsZero() bool {
+	return t.name == "" && len(t.values) == 0
+}
+
 // IsLabel returns true if the tag is a label (a tag without a value).
+//
+// The kind of a tag depends only on the number of its values: a label has
+// none, a single value tag has one and a multiple value tag has more than one,
+// so each tag is exactly one of them.
 func (t Tag) IsLabel() bool {
 	return len(t.values) == 0
 }
 
-// IsSingleValue returns true if the tag is a single value tag.
+// IsEmptyValue returns true if the tag is an empty-value tag, i.e. a label
+// parsed from the "name:" format by the [ParseVerbose] function.
+func (t Tag) IsEmptyValue() bool {
+	return t.emptyValue && t.IsLabel()
+}
+
+// IsSingleValue returns true if the tag is a single value tag (a tag with
+// exactly one value).
 func (t Tag) IsSingleValue() bool {
-	return len(t.values) == 0
+	return len(t.values) == 1
 }
 
-// IsMultiValue returns true if the tag is a multiple value tag.
+// IsMultiValue returns true if the tag is a multiple value tag (a tag with
+// more than one value).
 func (t Tag) IsMultiValue() bool {
 	return len(t.values) > 1
 }
@@ -69,18 +241,194 @@ func (t Tag) HasName(name string) bool {
 	return t.name == name
 }
 
-// HasValues returns true if the tag has all the values.
+// HasValues returns true if the tag has any of the values. To check that
+// the tag has all of them, use the [Tag.HasAllValues] method.
 func (t Tag) HasValues(values ...string) bool {
 	return slices.ContainsFunc(t.Values(), func(value string) bool {
 		return slices.Contains(values, value)
 	})
 }
 
+// HasAllValues returns true if the tag has every one of the values.
+func (t Tag) HasAllValues(values ...string) bool {
+	for _, v := range values {
+		if !slices.Contains(t.values, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValuesContaining returns the tag values that contain the substr. If there
+// are no such values (e.g. the tag is a label), it returns an empty slice.
+func (t Tag) ValuesContaining(substr string) []string {
+	values := []string{}
+	for _, v := range t.values {
+		if strings.Contains(v, substr) {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 // HasFunc returns true if the tag matches the fn.
 func (t Tag) HasFunc(fn MatchFunc) bool {
 	return fn(t)
 }
 
+// Equal returns true if the tags have the same name and the same values.
+// The order of the values doesn't matter.
+func (t Tag) Equal(other Tag) bool {
+	return t.name == other.name && len(t.values) == len(other.values) &&
+		slices.Equal(t.SortedUniqueValues(), other.SortedUniqueValues())
+}
+
+// Compare returns -1, 0 or 1 if the tag is less than, equal to or greater
+// than the other tag. Tags are compared by name, then by the number of values
+// and then by the sorted values.
+//
+// The order is consistent with the [Tag.Equal] method, i.e. Compare returns
+// 0 if and only if the tags are equal.
+func (t Tag) Compare(other Tag) int {
+	if c := strings.Compare(t.name, other.name); c != 0 {
+		return c
+	}
+	if len(t.values) != len(other.values) {
+		if len(t.values) < len(other.values) {
+			return -1
+		}
+		return 1
+	}
+	return slices.Compare(t.SortedUniqueValues(), other.SortedUniqueValues())
+}
+
+// EqualFold is like [Tag.Equal] but compares the name and values
+// case-insensitively (using [strings.EqualFold]).
+func (t Tag) EqualFold(other Tag) bool {
+	if !strings.EqualFold(t.name, other.name) {
+		return false
+	}
+	return containsFold(t.values, other.values) && containsFold(other.values, t.values)
+}
+
+// containsFold returns true if all values2 are in values1 (compared
+// case-insensitively).
+func containsFold(values1, values2 []string) bool {
+	for _, v2 := range values2 {
+		found := slices.ContainsFunc(values1, func(v1 string) bool {
+			return strings.EqualFold(v1, v2)
+		})
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Diff returns the sorted values the other tag has but the tag doesn't
+// (added) and the sorted values the tag has but the other tag doesn't
+// (removed). If the tags have different names, both are empty.
+func (t Tag) Diff(other Tag) (added, removed []string) {
+	if t.name != other.name {
+		return
+	}
+	return other.SubtractValues(t).SortedUniqueValues(), t.SubtractValues(other).SortedUniqueValues()
+}
+
+// SubtractValues returns a copy of the tag without the values of the other tag.
+//
+// If the tags have different names it returns the tag unchanged. If all values
+// are subtracted it returns a label.
+func (t Tag) SubtractValues(other Tag) Tag {
+	if t.name != other.name {
+		return t
+	}
+
+	var values []string
+	for _, v := range t.Values() {
+		if !slices.Contains(other.Values(), v) {
+			values = append(values, v)
+		}
+	}
+	return Tag{
+		name:   t.name,
+		values: values,
+	}
+}
+
+// WithValuesReplaced returns a copy of the tag with the values replaced by
+// the new values.
+//
+// The new values are handled the same way as by the [New] function. If there
+// are no (non-empty) new values it returns a label.
+func (t Tag) WithValuesReplaced(values ...string) (Tag, error) {
+	return New(t.name, values...)
+}
+
+// TrimValues returns a copy of the tag with leading and trailing whitespace
+// removed from the values. Values that become the same are made unique and
+// values that become empty are removed, see the [New] function docs.
+//
+// If any of the trimmed values is invalid (see the [ValueValidator] variable)
+// it returns the tag unchanged.
+func (t Tag) TrimValues() Tag {
+	if t.IsLabel() {
+		return t
+	}
+
+	values := make([]string, 0, len(t.values))
+	for _, v := range t.values {
+		values = append(values, strings.TrimSpace(v))
+	}
+	trimmed, err := New(t.name, values...)
+	if err != nil {
+		return t
+	}
+	return trimmed
+}
+
+// WithNamespace returns a copy of the tag with the name prefixed with the ns
+// and the [NamespaceSeparator]. Namespaces can be nested, i.e. a tag already
+// in a namespace is prefixed again. If the ns is empty, it returns the tag
+// unchanged.
+//
+// Example:
+//
+//	Must(Must(NewSingleValue("env", "prod")).WithNamespace("app")) -> "app.env:prod"
+func (t Tag) WithNamespace(ns string) (Tag, error) {
+	if ns == "" {
+		return t, nil
+	}
+	return New(ns+NamespaceSeparator+t.name, t.values...)
+}
+
+// Expand returns a copy of the tag with each value executed as
+// a [text/template] template with the data.
+//
+// Example:
+//
+//	Must(NewSingleValue("path", "{{.Dir}}/file")).Expand(map[string]string{"Dir": "/tmp"}) -> "path:/tmp/file"
+//
+// The returned tag is created with the [New] function so the expanded values
+// are made unique and empty ones are removed.
+func (t Tag) Expand(data any) (Tag, error) {
+	values := make([]string, 0, len(t.values))
+	for _, v := range t.values {
+		tmpl, err := template.New(t.name).Parse(v)
+		if err != nil {
+			return Tag{}, err
+		}
+
+		var b strings.Builder
+		err = tmpl.Execute(&b, data)
+		if err != nil {
+			return Tag{}, err
+		}
+		values = append(values, b.String())
+	}
+	return New(t.name, values...)
+}
+
 // String returns a string representation of the tag in the name[:value,...]
 // format.
 //
@@ -92,42 +440,204 @@ func (t Tag) HasFunc(fn MatchFunc) bool {
 //
 // This method is the reverse of the [Parse] function.
 func (t Tag) String() string {
-	if t.IsLabel() {
-		return t.name
-	} else {
-		return t.name + ":" + strings.Join(t.Values(), ",")
+	return string(t.AppendTo(nil))
+}
+
+// StringVerbose is like [Tag.String] but returns an empty-value tag (see
+// the [Tag.IsEmptyValue] method) in the name: format, so it can be
+// distinguished from a label.
+//
+// This method is the reverse of the [ParseVerbose] function.
+func (t Tag) StringVerbose() string {
+	if t.IsEmptyValue() {
+		return t.name + nameValueSeparator
+	}
+	return t.String()
+}
+
+// AppendTo appends the string representation of the tag (see the [Tag.String]
+// method) to the b and returns the extended slice.
+//
+// It's useful when building a string from many tags as the b can be reused.
+func (t Tag) AppendTo(b []byte) []byte {
+	b = append(b, t.name...)
+	for i, v := range t.values {
+		if i == 0 {
+			b = append(b, nameValueSeparator...)
+		} else {
+			b = append(b, valuesSeparator...)
+		}
+		b = append(b, v...)
+	}
+	return b
+}
+
+// Key returns a string that uniquely identifies the tag and can be used as
+// a map key.
+//
+// Unlike the [Tag.String] method, equal tags (see the [Tag.Equal] method)
+// always have the same key regardless of the order of their values, and
+// the name and values are quoted so separators in them can't cause collisions.
+//
+// Example:
+//
+//	Must(NewMultiValue("multi", "value2", "value1")).Key() -> `"multi":"value1","value2"`
+func (t Tag) Key() string {
+	b := strconv.AppendQuote(nil, t.name)
+	for i, v := range t.SortedUniqueValues() {
+		if i == 0 {
+			b = append(b, nameValueSeparator...)
+		} else {
+			b = append(b, valuesSeparator...)
+		}
+		b = strconv.AppendQuote(b, v)
 	}
+	return string(b)
 }
 
 // Parse tries to parse a string representation of a tag and returns
 // the corresponding [Tag] or an error.
 //
-// The string must be in the name[:value,...] format.
+// The string must be in the name[:value,...] format. The name ends at
+// the first ':' and everything after it is the list of values separated by
+// ',', i.e. the values can contain ':' (but not ',').
 //
 // Examples:
 //
 //	Must(Parse("label")) -> Tag{name: "label", values: nil}
 //	Must(Parse("single:value")) -> Tag{name: "single", values: []string{"value"}}
 //	Must(Parse("multi:value1,value2")) -> Tag{name: "multi", values: []string{"value1", "value2"}}
+//	Must(Parse("url:http://x,http://y")) -> Tag{name: "url", values: []string{"http://x", "http://y"}}
+//
+// The tag is created with the [New] function, so the same rules apply. Note
+// that empty values are removed, i.e. "name:" is parsed to the label "name".
 //
 // This function is the reverse of the [Tag.String] method.
 func Parse(tag string) (Tag, error) {
-	nameValues := strings.Split(tag, nameValueSeparator)
-	switch len(nameValues) {
-	case 1:
-		return Tag{
-			name:   nameValues[0],
-			values: []string{},
-		}, nil
-	case 2:
-		values := strings.Split(valuesSeparator, nameValues[1])
-		return Tag{
-			name:   nameValues[0],
-			values: values,
-		}, nil
-	default:
-		return Tag{}, fmt.Errorf("invalid format: '%s' (valid format: 'name[:value,...]')", tag)
+	name, values, found := strings.Cut(tag, nameValueSeparator)
+	if !found {
+		return New(name)
 	}
+	return New(name, strings.Split(values, valuesSeparator)...)
+}
+
+// ParseVerbose is like [Parse] but a tag in the "name:" format (i.e. with
+// the separator but without values) is parsed to an empty-value tag instead
+// of a plain label, see the [Tag.IsEmptyValue] method.
+//
+// This function is the reverse of the [Tag.StringVerbose] method.
+func ParseVerbose(tag string) (Tag, error) {
+	t, err := Parse(tag)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	t.emptyValue = t.IsLabel() && strings.Contains(tag, nameValueSeparator)
+	return t, nil
+}
+
+// ParseExpectMulti is like [Parse] but returns an error if the parsed tag is
+// not a multiple value tag, e.g. because the repeating values were removed.
+//
+// Examples:
+//
+//	Must(ParseExpectMulti("multi:value1,value2")) -> Tag{name: "multi", values: []string{"value1", "value2"}}
+//	ParseExpectMulti("multi:value,value") -> error
+func ParseExpectMulti(tag string) (Tag, error) {
+	t, err := Parse(tag)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	if !t.IsMultiValue() {
+		return Tag{}, fmt.Errorf("at least two unique values required: '%s'", tag)
+	}
+	return t, nil
+}
+
+// ParseStrict is like [Parse] but returns an error instead of cleaning up
+// the input, i.e. if the name or any of the values is empty or has leading or
+// trailing whitespace.
+//
+// Examples:
+//
+//	Must(ParseStrict("multi:value1,value2")) -> Tag{name: "multi", values: []string{"value1", "value2"}}
+//	ParseStrict("single:") -> error
+//	ParseStrict("multi:value1,") -> error
+//	ParseStrict(" label ") -> error
+func ParseStrict(tag string) (Tag, error) {
+	name, valueList, found := strings.Cut(tag, nameValueSeparator)
+	err := validateStrict("name", name)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	var values []string
+	if found {
+		values = strings.Split(valueList, valuesSeparator)
+		for _, v := range values {
+			err := validateStrict("value", v)
+			if err != nil {
+				return Tag{}, err
+			}
+		}
+	}
+
+	return New(name, values...)
+}
+
+// validateStrict returns an error if the s is empty or has leading or trailing
+// whitespace.
+func validateStrict(what, s string) error {
+	if s == "" {
+		return fmt.Errorf("%s required", what)
+	}
+	if strings.TrimSpace(s) != s {
+		return fmt.Errorf("invalid %s: '%s' (leading or trailing whitespace)", what, s)
+	}
+	return nil
+}
+
+// ParseHashtag is like [Parse] but also accepts a label in the hashtag format,
+// i.e. a name prefixed with '#'. A hashtag cannot have values.
+//
+// Examples:
+//
+//	Must(ParseHashtag("#urgent")) -> Tag{name: "urgent", values: nil}
+//	Must(ParseHashtag("single:value")) -> Tag{name: "single", values: []string{"value"}}
+//	ParseHashtag("#single:value") -> error
+func ParseHashtag(tag string) (Tag, error) {
+	name, found := strings.CutPrefix(tag, labelMarker)
+	if !found {
+		return Parse(tag)
+	}
+
+	if strings.Contains(name, nameValueSeparator) {
+		return Tag{}, fmt.Errorf("invalid format: '%s' (a hashtag cannot have values)", tag)
+	}
+	return NewLabel(name)
+}
+
+// ParseFlag parses a feature flag, i.e. a label that is disabled if its name
+// is suffixed with '!' and enabled otherwise. It returns the flag name, whether
+// it's enabled and the label. A flag cannot have values.
+//
+// Examples:
+//
+//	ParseFlag("feature") -> "feature", true, Tag{name: "feature", values: nil}, nil
+//	ParseFlag("feature!") -> "feature", false, Tag{name: "feature", values: nil}, nil
+//	ParseFlag("feature:value!") -> error
+func ParseFlag(flag string) (name string, enabled bool, t Tag, err error) {
+	if strings.Contains(flag, nameValueSeparator) {
+		return "", false, Tag{}, fmt.Errorf("invalid format: '%s' (a flag cannot have values)", flag)
+	}
+
+	name, disabled := strings.CutSuffix(flag, disabledMarker)
+	t, err = NewLabel(name)
+	if err != nil {
+		return "", false, Tag{}, err
+	}
+	return t.name, !disabled, t, nil
 }
 
 // NewLabel creates a label tag (a tag without a value).
@@ -153,6 +663,38 @@ func NewSingleValue(name, value string) (Tag, error) {
 	return tag, nil
 }
 
+// NewSingleValues creates a single value tag with the name for each of
+// the values. Empty values are skipped, so are all values if the name is empty.
+//
+// Example:
+//
+//	NewSingleValues("multi", "value1", "value2") -> []Tag{"multi:value1", "multi:value2"}
+func NewSingleValues(name string, values ...string) []Tag {
+	tags := make([]Tag, 0, len(values))
+	for _, v := range values {
+		tag, err := NewSingleValue(name, v)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// NewEnum creates a single value tag whose value must be one of the allowed
+// values.
+//
+// Example:
+//
+//	NewEnum("severity", []string{"low", "medium", "high"}, "high") -> "severity:high"
+func NewEnum(name string, allowed []string, value string) (Tag, error) {
+	if !slices.Contains(allowed, value) {
+		return Tag{}, fmt.Errorf("invalid value: '%s' (allowed values: '%s')", value,
+			strings.Join(allowed, valuesSeparator))
+	}
+	return NewSingleValue(name, value)
+}
+
 // NewMultiValue creates a multiple value tag (a tag with more than one value).
 //
 // The name and values cannot be empty strings. Repeating values will be removed,
@@ -170,11 +712,39 @@ func NewMultiValue(name string, values ...string) (Tag, error) {
 	return tag, nil
 }
 
+// NewMultiValueBounded is like [NewMultiValue] but the number of unique values
+// must also be between the minCount and maxCount (inclusive). The minCount
+// cannot be greater than the maxCount.
+func NewMultiValueBounded(name string, minCount, maxCount int, values ...string) (Tag, error) {
+	if minCount > maxCount {
+		return Tag{}, fmt.Errorf("invalid bounds: %d-%d (minimum greater than maximum)", minCount, maxCount)
+	}
+
+	tag, err := New(name, values...)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	count := len(tag.Values())
+	if count < minCount || count > maxCount {
+		return Tag{}, fmt.Errorf("invalid number of unique values: %d (allowed: %d-%d)", count, minCount, maxCount)
+	}
+	if count < 2 {
+		return Tag{}, fmt.Errorf("at least two unique values required")
+	}
+
+	return tag, nil
+}
+
 // New creates a tag with the name and values.
 //
 // The name cannot be an empty string. Empty-string values will be removed.
 // Repeating values will be removed, i.e. values will be made unique.
 //
+// The name and values are converted according to the [Case] variable before
+// the repeating values are removed. The remaining values are then validated
+// with the [ValueValidator] (if set).
+//
 // You can also use the convenience functions to create tags: [NewLabel],
 // [NewSingleValue] or [NewMultiValue].
 func New(name string, values ...string) (Tag, error) {
@@ -182,16 +752,99 @@ func New(name string, values ...string) (Tag, error) {
 		return Tag{}, fmt.Errorf("name required")
 	}
 
+	uniqueValues, err := newValues(values)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	return Tag{
+		name:   Case.apply(name),
+		values: uniqueValues,
+	}, nil
+}
+
+// newValues converts the values according to the [Case] variable, removes
+// empty and repeating values and validates the rest with the [ValueValidator]
+// (if set), see the [New] function docs.
+func newValues(values []string) ([]string, error) {
 	uniqueValues := make(map[string]string)
 	for _, v := range values {
+		v = Case.apply(v)
 		uniqueValues[v] = v
 	}
 	maps.DeleteFunc(uniqueValues, func(key, _ string) bool {
 		return strings.TrimSpace(key) == ""
 	})
 
-	return Tag{
-		name:   name,
-		values: maps.Values(uniqueValues),
-	}, nil
+	if ValueValidator != nil {
+		for v := range uniqueValues {
+			err := ValueValidator(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value: '%s' (%w)", v, err)
+			}
+		}
+	}
+	return maps.Values(uniqueValues), nil
+}
+
+// Report lists the values removed by the [NewWithReport] function.
+type Report struct {
+	// DroppedEmpty are the removed empty-string values.
+	DroppedEmpty []string
+	// DroppedDuplicate are the removed repeating values (each repetition
+	// is listed).
+	DroppedDuplicate []string
+}
+
+// NewWithReport is like [New] but also returns a [Report] listing the removed
+// values.
+func NewWithReport(name string, values ...string) (Tag, Report, error) {
+	tag, err := New(name, values...)
+	if err != nil {
+		return Tag{}, Report{}, err
+	}
+
+	var report Report
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		normalized := Case.apply(v)
+		switch {
+		case strings.TrimSpace(normalized) == "":
+			report.DroppedEmpty = append(report.DroppedEmpty, v)
+		case seen[normalized]:
+			report.DroppedDuplicate = append(report.DroppedDuplicate, v)
+		default:
+			seen[normalized] = true
+		}
+	}
+	return tag, report, nil
+}
+
+// RepairValue returns a copy of the t with the values containing ',' split
+// into multiple values, see the [New] function docs. It's useful for tags
+// persisted with multiple values joined into a single one.
+//
+// Example:
+//
+//	RepairValue(Must(NewSingleValue("multi", "a,b,c"))) -> "multi:a,b,c" (with three values)
+//
+// If no value contains ',' (or the t cannot be recreated), it returns the t
+// unchanged.
+func RepairValue(t Tag) Tag {
+	if !slices.ContainsFunc(t.values, func(v string) bool {
+		return strings.Contains(v, valuesSeparator)
+	}) {
+		return t
+	}
+
+	var values []string
+	for _, v := range t.values {
+		values = append(values, strings.Split(v, valuesSeparator)...)
+	}
+
+	repaired, err := New(t.name, values...)
+	if err != nil {
+		return t
+	}
+	return repaired
 }
@@ -1,7 +1,14 @@
 package tags
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"math/rand"
+	"strconv"
 	"strings"
 
 	"github.com/teris-io/shortid"
@@ -9,10 +16,17 @@ import (
 	"golang.org/x/exp/slices"
 )
 
+// ErrFrozen is returned (or used as a panic value by methods that don't
+// return an error) when changing a frozen group, see the [TagGroup.Freeze]
+// method.
+var ErrFrozen = errors.New("group is frozen")
+
 // TagGroup is a group of related tags.
 type TagGroup struct {
 	name string
 	tags map[string]Tag
+	// frozen is shared by the copies of the group as they share the tags too.
+	frozen *bool
 }
 
 // Name returns the group name.
@@ -21,7 +35,12 @@ func (g *TagGroup) Name() string {
 }
 
 // Rename renames the group. The newName cannot be an empty string.
+//
+// If the group is frozen, it returns [ErrFrozen].
 func (g *TagGroup) Rename(newName string) error {
+	if g.IsFrozen() {
+		return ErrFrozen
+	}
 	if strings.TrimSpace(newName) == "" {
 		return fmt.Errorf("name required")
 	}
@@ -37,25 +56,162 @@ func (g *TagGroup) Tags() []Tag {
 	return maps.Values(g.tags)
 }
 
+// Get returns the tag with the name and true, or an empty tag and false if
+// the group doesn't contain such tag.
+func (g *TagGroup) Get(name string) (Tag, bool) {
+	t, ok := g.tags[name]
+	return t, ok
+}
+
+// EachIndexed calls the fn for each group tag in the order sorted by name
+// with the index of the tag in that order.
+func (g *TagGroup) EachIndexed(fn func(i int, t Tag)) {
+	for i, t := range g.sortedTags() {
+		fn(i, t)
+	}
+}
+
+// Pairs returns an iterator over the (name, value) pairs of all the group
+// tags (see the [Tag.Pairs] method) sorted by name and value.
+func (g *TagGroup) Pairs() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, t := range g.sortedTags() {
+			for name, value := range t.sorted().Pairs() {
+				if !yield(name, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
 // Add adds tags to the group.
 //
 // If there are multiple tags with the same [Tag.Name], only the last one will
 // be added, i.e. the tag names must be unique.
+//
+// Tags are stored by name only, so a label and a tag with the same name
+// replace each other too. As the [New] function removes empty values, a tag
+// with just an empty value (e.g. parsed from "name:") is a label.
 func (g *TagGroup) Add(tags ...Tag) {
+	g.mustNotBeFrozen()
+	for _, t := range tags {
+		g.tags[t.name] = t
+	}
+}
+
+// ReplaceTag adds the tag to the group and returns true if it replaced
+// an existing tag with the same name.
+func (g *TagGroup) ReplaceTag(t Tag) (replaced bool) {
+	g.mustNotBeFrozen()
+	_, replaced = g.tags[t.name]
+	g.tags[t.name] = t
+	return
+}
+
+// SetEnum adds an enum tag (see the [NewEnum] function) to the group,
+// replacing the existing tag with the name, if any.
+//
+// If the value is not allowed, it returns an error and the group is not
+// changed. If the group is frozen, it returns [ErrFrozen].
+func (g *TagGroup) SetEnum(name string, allowed []string, value string) error {
+	if g.IsFrozen() {
+		return ErrFrozen
+	}
+
+	tag, err := NewEnum(name, allowed, value)
+	if err != nil {
+		return err
+	}
+
+	g.tags[tag.name] = tag
+	return nil
+}
+
+// AddWith adds tags to the group like the [TagGroup.Add] method, but if
+// the group already contains a tag with the same name, the fn decides what
+// is stored instead of replacing it. If the fn returns a tag with another
+// name, the existing tag is removed and the returned tag is stored under its
+// own name.
+//
+// Example (keeping the existing tags):
+//
+//	group.AddWith(func(old, new Tag) Tag { return old }, tags...)
+func (g *TagGroup) AddWith(fn MergeFunc, tags ...Tag) {
+	g.mustNotBeFrozen()
 	for _, t := range tags {
+		if existing, ok := g.tags[t.name]; ok {
+			delete(g.tags, t.name)
+			t = fn(existing, t)
+		}
 		g.tags[t.name] = t
 	}
 }
 
+// combineValues is a [MergeFunc] that adds the values of the new tag the old
+// tag doesn't have to the old tag. The name of the old tag is kept, so
+// the combined tag is stored under the same name.
+func combineValues(old, new Tag) Tag {
+	combined := old
+	combined.values = slices.Clone(old.values)
+	for _, v := range new.values {
+		if !slices.Contains(combined.values, v) {
+			combined.values = append(combined.values, v)
+		}
+	}
+	return combined
+}
+
+// AddValueToMatching adds the values to all tags matching the fn and returns
+// the number of changed tags. Values the tags already have are not added
+// again, so a tag that has all the values is not counted as changed.
+//
+// The values are handled the same way as by the [New] function. If any of
+// them is invalid (see the [ValueValidator] variable), it returns an error and
+// no tag is changed.
+func (g *TagGroup) AddValueToMatching(fn MatchFunc, values ...string) (changed int, err error) {
+	g.mustNotBeFrozen()
+	values, err = newValues(values)
+	if err != nil {
+		return 0, err
+	}
+
+	for name, t := range g.tags {
+		if !fn(t) {
+			continue
+		}
+
+		tag := combineValues(t, Tag{name: t.name, values: values})
+		if !tag.Equal(t) {
+			g.tags[name] = tag
+			changed++
+		}
+	}
+	return
+}
+
 // Contains returns true if the group contains the tags. The tags must match by
-// both name and values.
+// both name and values, see the [Tag.Equal] method.
 func (g *TagGroup) Contains(tags ...Tag) bool {
-	found := g.FindFunc(func(tag1 Tag) bool {
-		return slices.ContainsFunc(tags, func(tag2 Tag) bool {
-			return tag1.name == tag2.name && slices.Equal(tag1.Values(), tag2.Values())
-		})
-	})
-	return len(tags) == len(found)
+	for _, t := range tags {
+		stored, ok := g.tags[t.name]
+		if !ok || !stored.Equal(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsByName returns true if the group contains tags with the names of
+// the tags. Unlike the [TagGroup.Contains] method, the values are not
+// compared, e.g. a label matches a tag with the same name that has values.
+func (g *TagGroup) ContainsByName(tags ...Tag) bool {
+	for _, t := range tags {
+		if _, ok := g.tags[t.name]; !ok {
+			return false
+		}
+	}
+	return true
 }
 
 // ContainsNames returns true if the group contains tags matching the names.
@@ -63,6 +219,13 @@ func (g *TagGroup) ContainsNames(names ...string) bool {
 	return len(names) == len(g.FindNames(names...))
 }
 
+// ContainsLabel returns true if the group contains a label with the name.
+// A tag with the name that has values is not a match.
+func (g *TagGroup) ContainsLabel(name string) bool {
+	tag, ok := g.tags[name]
+	return ok && tag.IsLabel()
+}
+
 // ContainsValues returns true if the group contains tags matching all
 // the values, i.e. only tags that have all the values are considered matches.
 func (g *TagGroup) ContainsValues(values ...string) bool {
@@ -74,7 +237,86 @@ func (g *TagGroup) ContainsValues(values ...string) bool {
 // ContainsFunc returns true if the group contains tags matching the fn.
 // The tags must match by both name and values.
 func (g *TagGroup) ContainsFunc(fn MatchFunc) bool {
-	return len(g.FindFunc(fn)) != 0
+	for _, t := range g.tags {
+		if fn(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// EqualExcept returns true if the groups contain equal tags (see
+// the [Tag.Equal] method), ignoring the tags with the ignoreNames. The group
+// names are not compared.
+func (g *TagGroup) EqualExcept(other TagGroup, ignoreNames ...string) bool {
+	count := 0
+	for name, t := range g.tags {
+		if slices.Contains(ignoreNames, name) {
+			continue
+		}
+
+		otherTag, ok := other.tags[name]
+		if !ok || !t.Equal(otherTag) {
+			return false
+		}
+		count++
+	}
+
+	return count == other.CountFunc(func(tag Tag) bool {
+		return !slices.Contains(ignoreNames, tag.name)
+	})
+}
+
+// EqualOn returns true if the groups contain equal tags (see the [Tag.Equal]
+// method) with the names, ignoring all other tags. A name missing from both
+// groups is considered equal. The group names are not compared.
+func (g *TagGroup) EqualOn(other TagGroup, names ...string) bool {
+	for _, name := range names {
+		t, ok := g.tags[name]
+		otherTag, otherOk := other.tags[name]
+		if ok != otherOk || (ok && !t.Equal(otherTag)) {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualFunc returns true if each tag of the group matches some tag of
+// the other group according to the eq and vice versa. The group names are not
+// compared.
+//
+// Example (case-insensitive comparison):
+//
+//	group.EqualFunc(other, Tag.EqualFold)
+func (g *TagGroup) EqualFunc(other TagGroup, eq func(a, b Tag) bool) bool {
+	if len(g.tags) != len(other.tags) {
+		return false
+	}
+
+	for _, t := range g.tags {
+		found := other.ContainsFunc(func(tag Tag) bool {
+			return eq(t, tag)
+		})
+		if !found {
+			return false
+		}
+	}
+	for _, t := range other.tags {
+		found := g.ContainsFunc(func(tag Tag) bool {
+			return eq(tag, t)
+		})
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Equivalent returns true if the groups contain the same tags ignoring
+// the case of names and values, see the [Tag.EqualFold] method. The group
+// names are not compared.
+func (g *TagGroup) Equivalent(other TagGroup) bool {
+	return g.EqualFunc(other, Tag.EqualFold)
 }
 
 // FindNames returns tags matching the names.
@@ -92,9 +334,11 @@ func (g *TagGroup) FindValues(values ...string) []Tag {
 	})
 }
 
-// FindFunc returns tags matching the fn.
+// FindFunc returns tags matching the fn sorted by name.
+//
+// The other Find* methods use this method, so they return sorted tags too.
 func (g *TagGroup) FindFunc(fn MatchFunc) (found []Tag) {
-	for _, t := range g.Tags() {
+	for _, t := range g.sortedTags() {
 		if fn(t) {
 			found = append(found, t)
 		}
@@ -102,14 +346,155 @@ func (g *TagGroup) FindFunc(fn MatchFunc) (found []Tag) {
 	return
 }
 
+// NamesWithValue returns the sorted names of the tags that have the value.
+// If there are no such tags, it returns an empty slice.
+func (g *TagGroup) NamesWithValue(value string) []string {
+	names := []string{}
+	for _, t := range g.sortedTags() {
+		if slices.Contains(t.values, value) {
+			names = append(names, t.name)
+		}
+	}
+	return names
+}
+
+// FindConflicting returns tags that have more than one value from any of
+// the exclusive sets of mutually exclusive values.
+//
+// Example (a tag cannot be both open and closed):
+//
+//	group.FindConflicting([][]string{{"open", "closed"}})
+func (g *TagGroup) FindConflicting(exclusive [][]string) []Tag {
+	return g.FindFunc(func(tag Tag) bool {
+		for _, set := range exclusive {
+			count := 0
+			for _, v := range set {
+				if slices.Contains(tag.values, v) {
+					count++
+				}
+			}
+			if count > 1 {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// FindCaseCollisions returns groups of tags whose names differ only by case,
+// e.g. "Env" and "env". The groups are sorted by the lowercased name and
+// the tags in them by name.
+func (g *TagGroup) FindCaseCollisions() (collisions [][]Tag) {
+	var folded []string
+	byFolded := make(map[string][]Tag)
+	for _, t := range g.sortedTags() {
+		name := strings.ToLower(t.name)
+		if _, ok := byFolded[name]; !ok {
+			folded = append(folded, name)
+		}
+		byFolded[name] = append(byFolded[name], t)
+	}
+
+	slices.Sort(folded)
+	for _, name := range folded {
+		if len(byFolded[name]) > 1 {
+			collisions = append(collisions, byFolded[name])
+		}
+	}
+	return
+}
+
+// FindFirst returns the first tag (in the order sorted by name) matching
+// the fn and true, or an empty tag and false if there is no such tag.
+func (g *TagGroup) FindFirst(fn MatchFunc) (first Tag, found bool) {
+	for _, t := range g.tags {
+		if (!found || t.name < first.name) && fn(t) {
+			first, found = t, true
+		}
+	}
+	return
+}
+
+// TopNames returns the names of up to n tags with the most values in
+// descending order of the number of values. Tags with the same number of
+// values are sorted by name.
+func (g *TagGroup) TopNames(n int) []string {
+	tags := g.sortedTags()
+	slices.SortStableFunc(tags, func(tag1, tag2 Tag) bool {
+		return len(tag1.values) > len(tag2.values)
+	})
+
+	names := []string{}
+	for _, t := range tags {
+		if len(names) >= n {
+			break
+		}
+		names = append(names, t.name)
+	}
+	return names
+}
+
+// Sample returns up to n randomly chosen tags using the r, so the same r
+// (e.g. created with the same seed) gives the same sample. If n is greater
+// than or equal to the number of tags, it returns all tags sorted by name.
+func (g *TagGroup) Sample(n int, r *rand.Rand) []Tag {
+	tags := g.sortedTags()
+	if n >= len(tags) {
+		return tags
+	}
+	if n <= 0 {
+		return []Tag{}
+	}
+
+	r.Shuffle(len(tags), func(i, j int) {
+		tags[i], tags[j] = tags[j], tags[i]
+	})
+	return tags[:n]
+}
+
+// CountNames returns the number of tags matching the names.
+func (g *TagGroup) CountNames(names ...string) int {
+	return g.CountFunc(func(tag Tag) bool {
+		return slices.Contains(names, tag.Name())
+	})
+}
+
+// CountValues returns the number of tags that have any of the values, see
+// the [Tag.HasValues] method.
+func (g *TagGroup) CountValues(values ...string) int {
+	return g.CountFunc(func(tag Tag) bool {
+		return tag.HasValues(values...)
+	})
+}
+
+// CountValue returns the number of tags that have the value.
+func (g *TagGroup) CountValue(value string) int {
+	return g.CountFunc(func(tag Tag) bool {
+		return slices.Contains(tag.values, value)
+	})
+}
+
+// CountFunc returns the number of tags matching the fn.
+//
+// Unlike len(g.FindFunc(fn)) it doesn't allocate.
+func (g *TagGroup) CountFunc(fn MatchFunc) (count int) {
+	for _, t := range g.tags {
+		if fn(t) {
+			count++
+		}
+	}
+	return
+}
+
 // Remove removes the matching tags from the group. The tags must match by both
-// name and values.
+// name and values, see the [Tag.Equal] method.
 func (g *TagGroup) Remove(tags ...Tag) {
-	g.RemoveFunc(func(tag Tag) bool {
-		return slices.ContainsFunc(tags, func(tag Tag) bool {
-			return g.Contains(tag)
-		})
-	})
+	g.mustNotBeFrozen()
+	for _, t := range tags {
+		if stored, ok := g.tags[t.name]; ok && stored.Equal(t) {
+			delete(g.tags, t.name)
+		}
+	}
 }
 
 // RemoveNames removes tags matching the names from the group.
@@ -127,8 +512,21 @@ func (g *TagGroup) RemoveValues(values ...string) {
 	})
 }
 
+// KeepValues removes all tags from the group except the ones that have every
+// one of the values (see the [Tag.HasAllValues] method). Labels are always
+// removed.
+//
+// This method is the retaining counterpart of the [TagGroup.RemoveValues]
+// method.
+func (g *TagGroup) KeepValues(values ...string) {
+	g.RemoveFunc(func(tag Tag) bool {
+		return tag.IsLabel() || !tag.HasAllValues(values...)
+	})
+}
+
 // RemoveFunc removes tags matching the fn from the group.
 func (g *TagGroup) RemoveFunc(fn MatchFunc) {
+	g.mustNotBeFrozen()
 	for _, t := range g.Tags() {
 		if fn(t) {
 			delete(g.tags, t.name)
@@ -136,6 +534,70 @@ func (g *TagGroup) RemoveFunc(fn MatchFunc) {
 	}
 }
 
+// RemoveFuncReturning removes tags matching the fn from the group and returns
+// the removed tags.
+func (g *TagGroup) RemoveFuncReturning(fn MatchFunc) (removed []Tag) {
+	g.mustNotBeFrozen()
+	for _, t := range g.Tags() {
+		if fn(t) {
+			delete(g.tags, t.name)
+			removed = append(removed, t)
+		}
+	}
+	return
+}
+
+// PruneValues removes the values that are used by less than minCount tags
+// and returns the number of removed values (counting each removal from a tag).
+//
+// Tags that lose all their values are kept as labels. To remove them use
+// the [TagGroup.RemoveFunc] method with the [Tag.IsLabel] method.
+func (g *TagGroup) PruneValues(minCount int) (removed int) {
+	g.mustNotBeFrozen()
+	counts := make(map[string]int)
+	for _, t := range g.tags {
+		for _, v := range t.values {
+			counts[v]++
+		}
+	}
+
+	for name, t := range g.tags {
+		var values []string
+		for _, v := range t.values {
+			if counts[v] >= minCount {
+				values = append(values, v)
+			}
+		}
+
+		if len(values) != len(t.values) {
+			removed += len(t.values) - len(values)
+			g.tags[name] = Tag{name: t.name, values: values}
+		}
+	}
+	return
+}
+
+// Sanitize recreates all the group tags with the [New] function, removing
+// empty and repeating values, and returns the number of changed tags.
+//
+// Tags with an invalid name are removed (and counted as changed).
+func (g *TagGroup) Sanitize() (changed int) {
+	g.mustNotBeFrozen()
+	for name, t := range g.tags {
+		sanitized, err := New(t.name, t.values...)
+		if err == nil && name == sanitized.name && sanitized.Equal(t) {
+			continue
+		}
+
+		changed++
+		delete(g.tags, name)
+		if err == nil {
+			g.tags[sanitized.name] = sanitized
+		}
+	}
+	return
+}
+
 // SortNames sorts the tags by their name in ascending (desc == false)
 // or descending (desc == true) order.
 func (g *TagGroup) SortNames(desc bool) {
@@ -149,9 +611,405 @@ func (g *TagGroup) SortNames(desc bool) {
 
 // SortFunc sorts the tags by fn.
 func (g *TagGroup) SortFunc(fn LessFunc) {
+	g.mustNotBeFrozen()
 	slices.SortStableFunc(g.Tags(), fn)
 }
 
+// SortAllValuesFunc sorts the values of all the group tags by the less
+// function, see the [Tag.SortValuesFunc] method.
+func (g *TagGroup) SortAllValuesFunc(less func(a, b string) bool) {
+	g.mustNotBeFrozen()
+	for name, t := range g.tags {
+		g.tags[name] = t.SortValuesFunc(less)
+	}
+}
+
+// Validate returns an error describing the first (in the order sorted by
+// name) tag that violates the rules of the [New] function (i.e. has an empty
+// name, an empty value or a repeating value) or is stored under another name
+// than its own. It returns nil for a valid group.
+//
+// It's useful for debugging groups created or changed in unusual ways (e.g.
+// via reflection).
+func (g *TagGroup) Validate() error {
+	names := maps.Keys(g.tags)
+	slices.Sort(names)
+	for _, name := range names {
+		t := g.tags[name]
+		if name != t.name {
+			return fmt.Errorf("tag '%s' stored as '%s'", t.name, name)
+		}
+		if strings.TrimSpace(t.name) == "" {
+			return fmt.Errorf("tag '%s': name required", name)
+		}
+
+		for i, v := range t.values {
+			if strings.TrimSpace(v) == "" {
+				return fmt.Errorf("tag '%s': empty value", name)
+			}
+			if slices.Contains(t.values[:i], v) {
+				return fmt.Errorf("tag '%s': repeating value '%s'", name, v)
+			}
+		}
+	}
+	return nil
+}
+
+// Freeze makes the group read-only. Methods changing a frozen group either
+// return [ErrFrozen] or panic with it. A frozen group cannot be unfrozen, but
+// its copy created with the [TagGroup.Clone] method is not frozen.
+//
+// Copies of a group (e.g. returned by the [TagStore.Group] method) share its
+// tags, so they share the frozen state too, i.e. freezing any of them freezes
+// all of them.
+//
+// It's useful for groups that are shared or cached.
+func (g *TagGroup) Freeze() {
+	if g.frozen == nil {
+		g.frozen = new(bool)
+	}
+	*g.frozen = true
+}
+
+// IsFrozen returns true if the group is frozen, see the [TagGroup.Freeze]
+// method.
+func (g *TagGroup) IsFrozen() bool {
+	return g.frozen != nil && *g.frozen
+}
+
+// mustNotBeFrozen panics with [ErrFrozen] if the group is frozen.
+func (g *TagGroup) mustNotBeFrozen() {
+	if g.IsFrozen() {
+		panic(ErrFrozen)
+	}
+}
+
+// Clone returns a deep copy of the group. The copy is not frozen.
+func (g *TagGroup) Clone() TagGroup {
+	group := newGroup(g.name, len(g.tags))
+	for name, t := range g.tags {
+		c := t
+		c.values = slices.Clone(t.values)
+		group.tags[name] = c
+	}
+	return group
+}
+
+// CloneAs returns a deep copy of the group (see the [TagGroup.Clone] method)
+// renamed to the name. The name cannot be an empty string.
+func (g *TagGroup) CloneAs(name string) (TagGroup, error) {
+	group := g.Clone()
+	err := group.Rename(name)
+	if err != nil {
+		return TagGroup{}, err
+	}
+	return group, nil
+}
+
+// Apply passes a copy of the group through the transforms in order and
+// returns the result. The group itself is not changed.
+//
+// Example:
+//
+//	group.Apply(LowercaseValues, SortValues)
+func (g *TagGroup) Apply(transforms ...TransformFunc) TagGroup {
+	group := g.Clone()
+	for _, transform := range transforms {
+		group = transform(group)
+	}
+	return group
+}
+
+// LowercaseValues is a [TransformFunc] that returns a copy of the group with
+// the values of all tags converted to lower case. Values that become the same
+// are made unique.
+func LowercaseValues(g TagGroup) TagGroup {
+	group := newGroup(g.name, len(g.tags))
+	for name, t := range g.tags {
+		values := make([]string, 0, len(t.values))
+		for _, v := range t.values {
+			v = strings.ToLower(v)
+			if !slices.Contains(values, v) {
+				values = append(values, v)
+			}
+		}
+		t.values = values
+		group.tags[name] = t
+	}
+	return group
+}
+
+// SortValues is a [TransformFunc] that returns a copy of the group with
+// the values of all tags sorted in ascending order.
+func SortValues(g TagGroup) TagGroup {
+	group := newGroup(g.name, len(g.tags))
+	for name, t := range g.tags {
+		group.tags[name] = t.sorted()
+	}
+	return group
+}
+
+// Flatten returns a tag with the name and the values of all the group tags.
+//
+// Repeating values will be removed, see the [New] function docs. If no group
+// tag has a value it returns a label.
+func (g *TagGroup) Flatten(name string) (Tag, error) {
+	var values []string
+	for _, t := range g.tags {
+		values = append(values, t.Values()...)
+	}
+	return New(name, values...)
+}
+
+// Namespaced returns a copy of the group with the names of all tags prefixed
+// with the ns, see the [Tag.WithNamespace] method. As all names get the same
+// prefix, unique names stay unique.
+func (g *TagGroup) Namespaced(ns string) (TagGroup, error) {
+	group := newGroup(g.name, len(g.tags))
+	for _, t := range g.tags {
+		tag, err := t.WithNamespace(ns)
+		if err != nil {
+			return TagGroup{}, err
+		}
+		group.tags[tag.name] = tag
+	}
+	return group, nil
+}
+
+// IntersectNames returns a group with the name of the group and its tags whose
+// names are also in the other group. The tag values are kept as they are in
+// the group, i.e. they are not compared with the other group.
+func (g *TagGroup) IntersectNames(other TagGroup) TagGroup {
+	group := newGroup(g.name, 0)
+	for name, t := range g.tags {
+		if _, ok := other.tags[name]; ok {
+			group.tags[name] = t
+		}
+	}
+	return group
+}
+
+// Remap returns a copy of the group with the tag names replaced according to
+// the nameMap and the values replaced according to the valueMap. Names and
+// values not in the maps are kept.
+//
+// If several tags end up with the same name they are combined into one tag
+// with the values of all of them. Mappings to an empty name are ignored.
+//
+// The remapped tags are created with the [New] function, so it returns
+// an error if any of them is invalid (see the [ValueValidator] variable).
+func (g *TagGroup) Remap(nameMap map[string]string, valueMap map[string]string) (TagGroup, error) {
+	group := newGroup(g.name, 0)
+	for _, t := range g.tags {
+		name := t.name
+		if newName, ok := nameMap[name]; ok && strings.TrimSpace(newName) != "" {
+			name = newName
+		}
+
+		values := make([]string, 0, len(t.values))
+		for _, v := range t.values {
+			if newValue, ok := valueMap[v]; ok {
+				v = newValue
+			}
+			values = append(values, v)
+		}
+		tag, err := New(name, values...)
+		if err != nil {
+			return TagGroup{}, fmt.Errorf("invalid tag '%s': %w", t.name, err)
+		}
+		group.AddWith(combineValues, tag)
+	}
+	return group, nil
+}
+
+// Canonical returns a canonical string representation of the group tags.
+//
+// The tags are sorted by name and their values are sorted too, one tag per line
+// in the [Tag.String] format. The group name is not included. Groups with
+// the same tags always produce the same output, which makes it suitable for
+// storing in version control.
+func (g *TagGroup) Canonical() string {
+	var b strings.Builder
+	_ = g.writeCanonical(&b)
+	return b.String()
+}
+
+// writeCanonical writes the canonical string representation of the group tags
+// (see the [TagGroup.Canonical] method) to the w tag by tag.
+func (g *TagGroup) writeCanonical(w io.Writer) error {
+	var b []byte
+	for _, t := range g.sortedTags() {
+		b = append(t.sorted().AppendTo(b[:0]), '\n')
+		_, err := w.Write(b)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Conflict is a tag name present in both groups merged by
+// the [TagGroup.MergeReporting] method with different values.
+type Conflict struct {
+	Name string
+	// Tag is the tag from the group (the one kept in the merged group).
+	Tag Tag
+	// Other is the tag from the other group.
+	Other Tag
+}
+
+// MergeReporting returns a copy of the group with the tags of the other group
+// added, and the conflicts sorted by name, i.e. the tags present in both
+// groups with different values (see the [Tag.Equal] method). For conflicts
+// the tags of the group are kept.
+func (g *TagGroup) MergeReporting(other TagGroup) (TagGroup, []Conflict) {
+	var conflicts []Conflict
+	group := g.Clone()
+	for _, otherTag := range other.sortedTags() {
+		t, ok := group.tags[otherTag.name]
+		if !ok {
+			group.tags[otherTag.name] = otherTag
+		} else if !t.Equal(otherTag) {
+			conflicts = append(conflicts, Conflict{Name: t.name, Tag: t, Other: otherTag})
+		}
+	}
+	return group, conflicts
+}
+
+// Patch returns a textual patch describing how to turn the group into
+// the other group, one line per tag sorted by name: removed tags are prefixed
+// with '-', added tags with '+' and changed tags are listed as removed and
+// added. Values are sorted and the group names are not compared.
+//
+// Example:
+//
+//	-env:dev
+//	+env:prod
+//	+label
+func (g *TagGroup) Patch(other TagGroup) string {
+	names := append(maps.Keys(g.tags), maps.Keys(other.tags)...)
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		t, ok := g.tags[name]
+		otherTag, otherOk := other.tags[name]
+		if ok && otherOk && t.Equal(otherTag) {
+			continue
+		}
+
+		if ok {
+			b.WriteString("-" + t.sorted().String() + "\n")
+		}
+		if otherOk {
+			b.WriteString("+" + otherTag.sorted().String() + "\n")
+		}
+	}
+	return b.String()
+}
+
+// WriteTo writes the canonical string representation of the group tags (see
+// the [TagGroup.Canonical] method) to the w and returns the number of written
+// bytes. It implements the [io.WriterTo] interface.
+//
+// Note that the group is neither an [io.Reader] nor an [io.Writer], so it
+// cannot be passed to [io.Copy] directly.
+func (g *TagGroup) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := g.writeCanonical(cw)
+	return cw.n, err
+}
+
+// ReadFrom reads tags from the r, one tag per line (parsed with the [Parse]
+// function), adds them to the group and returns the number of read bytes.
+// Empty lines are skipped. It implements the [io.ReaderFrom] interface.
+//
+// If any line is not a valid tag, no tags are added. If the group is frozen,
+// it returns [ErrFrozen]. The zero value of the group becomes an anonymous
+// group, see the [NewAnonymousGroup] function.
+//
+// This method is the reverse of the [TagGroup.WriteTo] method.
+func (g *TagGroup) ReadFrom(r io.Reader) (int64, error) {
+	if g.IsFrozen() {
+		return 0, ErrFrozen
+	}
+
+	data, err := io.ReadAll(r)
+	n := int64(len(data))
+	if err != nil {
+		return n, err
+	}
+
+	var tags []Tag
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		tag, err := Parse(line)
+		if err != nil {
+			return n, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if g.tags == nil {
+		*g = NewAnonymousGroup()
+	}
+	g.Add(tags...)
+	return n, nil
+}
+
+// countingWriter is an [io.Writer] counting the bytes written to the w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write writes the p to the w and counts the written bytes.
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// ContentID returns an identifier (a hex-encoded SHA-256 hash) of the group
+// tags. The group name is not included, so groups with the same tags have
+// the same ContentID regardless of their names. To include the name use
+// the [TagGroup.ContentIDNamed] method.
+func (g *TagGroup) ContentID() string {
+	return g.contentID(nil)
+}
+
+// ContentIDNamed is like [TagGroup.ContentID] but includes the group name,
+// i.e. groups with the same tags but different names have different
+// identifiers.
+func (g *TagGroup) ContentIDNamed() string {
+	return g.contentID(strconv.AppendQuote(nil, g.name))
+}
+
+// contentID returns the hex-encoded SHA-256 hash of the prefix followed by
+// the keys (see the [Tag.Key] method) of the tags sorted by name.
+func (g *TagGroup) contentID(prefix []byte) string {
+	hash := sha256.New()
+	hash.Write(prefix)
+	for _, t := range g.sortedTags() {
+		hash.Write([]byte("\n" + t.Key()))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// sortedTags returns the group tags sorted by name.
+func (g *TagGroup) sortedTags() []Tag {
+	tags := g.Tags()
+	slices.SortFunc(tags, func(tag1, tag2 Tag) bool {
+		return tag1.name < tag2.name
+	})
+	return tags
+}
+
 // NewGroupWithGeneratedName creates a group with a generated name and adds
 // the specified tags to it.
 //
@@ -160,19 +1018,127 @@ func NewGroupWithGeneratedName(tags ...Tag) TagGroup {
 	return Must(NewGroup(shortid.MustGenerate(), tags...))
 }
 
+// NewAnonymousGroup creates a group with an empty name and adds the specified
+// tags to it. The group can be named later with the [TagGroup.Rename] method.
+//
+// Unlike the [NewGroupWithGeneratedName] function, the name is not generated.
+// The group name is not part of the canonical forms of a group (e.g.
+// the [TagGroup.Canonical] method), so they work with anonymous groups too.
+//
+// The tag names must be unique, see the [TagGroup.Add] method docs.
+func NewAnonymousGroup(tags ...Tag) TagGroup {
+	group := newGroup("", len(tags))
+	group.Add(tags...)
+	return group
+}
+
 // NewGroup creates a group with the specified name and adds the provided tags
 // to it.
 //
 // The group name cannot be an empty string.
 // The tag names must be unique, see the [TagGroup.Add] method docs.
 func NewGroup(name string, tags ...Tag) (TagGroup, error) {
-	group := TagGroup{}
+	group := newGroup("", len(tags))
 	err := group.Rename(name)
 	if err != nil {
 		return TagGroup{}, err
 	}
 
-	group.tags = map[string]Tag{}
 	group.Add(tags...)
 	return group, nil
 }
+
+// newGroup returns an empty group with the name. The name is not validated.
+func newGroup(name string, size int) TagGroup {
+	return TagGroup{name: name, tags: make(map[string]Tag, size), frozen: new(bool)}
+}
+
+// ParseGroupTags creates a group with the specified name and adds the tags
+// parsed from the tagStrings (with the [Parse] function) to it. The returned
+// error contains the tag string that cannot be parsed.
+//
+// The group name cannot be an empty string.
+// The tag names must be unique, see the [TagGroup.Add] method docs.
+func ParseGroupTags(name string, tagStrings ...string) (TagGroup, error) {
+	tags := make([]Tag, 0, len(tagStrings))
+	for _, s := range tagStrings {
+		tag, err := Parse(s)
+		if err != nil {
+			return TagGroup{}, fmt.Errorf("invalid tag '%s': %w", s, err)
+		}
+		tags = append(tags, tag)
+	}
+	return NewGroup(name, tags...)
+}
+
+// NewGroupMerging is like [NewGroup] but combines the values of tags with
+// the same name instead of keeping just the last one.
+//
+// The tags are sorted by name once and the values of each name are combined
+// in a single pass, so it's faster than adding the tags one by one for large
+// inputs with many repeating names.
+//
+// Tags whose names are the same after applying the [Case] are combined too.
+//
+// The combined tags are created with the [New] function, so it returns
+// an error if any of them is invalid, e.g. a tag without a name (like Tag{})
+// or with a value rejected by the [ValueValidator].
+func NewGroupMerging(name string, tags ...Tag) (TagGroup, error) {
+	group, err := NewGroup(name)
+	if err != nil {
+		return TagGroup{}, err
+	}
+
+	// The tags are sorted by their names with the Case applied, as that's
+	// the name New stores them under.
+	type namedTag struct {
+		name string
+		tag  Tag
+	}
+	sorted := make([]namedTag, 0, len(tags))
+	for _, t := range tags {
+		sorted = append(sorted, namedTag{name: Case.apply(t.name), tag: t})
+	}
+	slices.SortStableFunc(sorted, func(tag1, tag2 namedTag) bool {
+		return tag1.name < tag2.name
+	})
+
+	for start := 0; start < len(sorted); {
+		end := start
+		var values []string
+		for ; end < len(sorted) && sorted[end].name == sorted[start].name; end++ {
+			values = append(values, sorted[end].tag.values...)
+		}
+
+		tag, err := New(sorted[start].name, values...)
+		if err != nil {
+			return TagGroup{}, fmt.Errorf("invalid tag '%s': %w", sorted[start].tag.name, err)
+		}
+		group.tags[tag.name] = tag
+		start = end
+	}
+	return group, nil
+}
+
+// GroupBy groups the tags by the key returned by the keyFn. Each group is named
+// by its key. Tags with an empty key are skipped.
+//
+// Unlike the [TagGroup.Add] method, the values of tags with the same name in
+// a group are combined.
+func GroupBy(tags []Tag, keyFn func(Tag) string) map[string]TagGroup {
+	groups := make(map[string]TagGroup)
+	for _, t := range tags {
+		key := keyFn(t)
+		group, ok := groups[key]
+		if !ok {
+			var err error
+			group, err = NewGroup(key)
+			if err != nil {
+				continue
+			}
+			groups[key] = group
+		}
+		group.AddWith(combineValues, t)
+	}
+	return groups
+}
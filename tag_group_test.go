@@ -0,0 +1,1323 @@
+package tags
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestCanonical(t *testing.T) {
+	group1 := Must(ParseGroupTags("group1", "multi:b,a,c", "label", "single:value"))
+	group2 := Must(ParseGroupTags("group2", "single:value", "multi:c,b,a", "label"))
+
+	want := "label\nmulti:a,b,c\nsingle:value\n"
+	if got := group1.Canonical(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if group1.Canonical() != group2.Canonical() {
+		t.Errorf("%q != %q", group1.Canonical(), group2.Canonical())
+	}
+}
+
+func TestCanonicalValueChange(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a:1", "b:2", "c:3"))
+	before := strings.Split(group.Canonical(), "\n")
+	group.Add(Must(Parse("b:4")))
+	after := strings.Split(group.Canonical(), "\n")
+
+	if len(before) != len(after) {
+		t.Fatalf("line count changed: %d -> %d", len(before), len(after))
+	}
+	var changed []string
+	for i := range before {
+		if before[i] != after[i] {
+			changed = append(changed, before[i]+" -> "+after[i])
+		}
+	}
+	if len(changed) != 1 || changed[0] != "b:2 -> b:4" {
+		t.Errorf("got changed lines %q, want just b:2 -> b:4", changed)
+	}
+}
+
+func TestRemoveFuncReturning(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a:1", "b:1,2", "c:3", "label"))
+	removed := group.RemoveFuncReturning(func(tag Tag) bool {
+		return tag.HasValues("1")
+	})
+
+	removedGroup := NewAnonymousGroup(removed...)
+	if got, want := removedGroup.Canonical(), "a:1\nb:1,2\n"; got != want {
+		t.Errorf("removed %q, want %q", got, want)
+	}
+	if got, want := group.Canonical(), "c:3\nlabel\n"; got != want {
+		t.Errorf("kept %q, want %q", got, want)
+	}
+}
+
+func TestRemoveFuncReturningNoMatch(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a:1", "label"))
+	removed := group.RemoveFuncReturning(func(Tag) bool { return false })
+
+	if len(removed) != 0 {
+		t.Errorf("removed %v, want nothing", removed)
+	}
+	if got, want := group.Canonical(), "a:1\nlabel\n"; got != want {
+		t.Errorf("kept %q, want %q", got, want)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a:1,2", "b:2,3", "c:3", "label"))
+	tag, err := group.Flatten("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := Must(Parse("all:1,2,3")); !tag.Equal(want) {
+		t.Errorf("got %v, want %v", tag, want)
+	}
+}
+
+func TestFlattenEmptyGroup(t *testing.T) {
+	group := NewAnonymousGroup()
+	tag, err := group.Flatten("all")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tag.IsLabel() || tag.Name() != "all" {
+		t.Errorf("got %v, want the label all", tag)
+	}
+}
+
+func TestCount(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a:1,2", "b:2,3", "c:3", "label"))
+
+	for _, names := range [][]string{{"a"}, {"a", "c"}, {"a", "missing"}, {}} {
+		if got, want := group.CountNames(names...), len(group.FindNames(names...)); got != want {
+			t.Errorf("CountNames(%q): got %d, want %d", names, got, want)
+		}
+	}
+	valueTests := []struct {
+		values []string
+		want   int
+	}{
+		{[]string{"2"}, 2},
+		{[]string{"3"}, 2},
+		{[]string{"1", "2"}, 2},
+		{[]string{"1", "3"}, 3},
+		{[]string{"1", "missing"}, 1},
+		{[]string{"missing"}, 0},
+	}
+	for _, tt := range valueTests {
+		got := group.CountValues(tt.values...)
+		if got != tt.want {
+			t.Errorf("CountValues(%q): got %d, want %d", tt.values, got, tt.want)
+		}
+		if want := len(group.FindValues(tt.values...)); got != want {
+			t.Errorf("CountValues(%q): got %d, FindValues found %d", tt.values, got, want)
+		}
+	}
+
+	fn := func(tag Tag) bool { return tag.IsMultiValue() }
+	if got, want := group.CountFunc(fn), len(group.FindFunc(fn)); got != want || got != 2 {
+		t.Errorf("CountFunc: got %d, want %d (2)", got, want)
+	}
+}
+
+func TestRemap(t *testing.T) {
+	tests := []struct {
+		name     string
+		nameMap  map[string]string
+		valueMap map[string]string
+		want     string
+	}{
+		{"name", map[string]string{"env": "stage"}, nil, "label\nregion:eu\nstage:prod\n"},
+		{"value", nil, map[string]string{"prod": "production"}, "env:production\nlabel\nregion:eu\n"},
+		{"collision", map[string]string{"region": "env"}, nil, "env:eu,prod\nlabel\n"},
+		{"empty name", map[string]string{"env": ""}, nil, "env:prod\nlabel\nregion:eu\n"},
+	}
+	for _, tt := range tests {
+		group := Must(ParseGroupTags("group", "env:prod", "region:eu", "label"))
+		remapped, err := group.Remap(tt.nameMap, tt.valueMap)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+
+		if got := remapped.Canonical(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		if got, want := group.Canonical(), "env:prod\nlabel\nregion:eu\n"; got != want {
+			t.Errorf("%s: original changed to %q", tt.name, got)
+		}
+	}
+}
+
+func TestPruneValues(t *testing.T) {
+	// "common" is used 3 times, "pair" twice and "rare" once.
+	group := Must(ParseGroupTags("group", "a:common,pair", "b:common,pair,rare", "c:common", "d:rare2", "label"))
+	removed := group.PruneValues(2)
+
+	if removed != 2 {
+		t.Errorf("removed %d values, want 2", removed)
+	}
+	if got, want := group.Canonical(), "a:common,pair\nb:common,pair\nc:common\nd\nlabel\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPruneValuesNothingToPrune(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a:x", "b:x"))
+	if removed := group.PruneValues(2); removed != 0 {
+		t.Errorf("removed %d values, want 0", removed)
+	}
+	if got, want := group.Canonical(), "a:x\nb:x\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEqualExcept(t *testing.T) {
+	group := Must(ParseGroupTags("group1", "env:prod", "id:1", "ts:100"))
+	tests := []struct {
+		name  string
+		other []string
+		want  bool
+	}{
+		{"differ in ignored", []string{"env:prod", "id:2", "ts:200"}, true},
+		{"differ in not ignored", []string{"env:dev", "id:1", "ts:100"}, false},
+		{"ignored in one group only", []string{"env:prod"}, true},
+		{"extra tag", []string{"env:prod", "extra"}, false},
+	}
+	for _, tt := range tests {
+		other := Must(ParseGroupTags("group2", tt.other...))
+		if got := group.EqualExcept(other, "id", "ts"); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		if got := other.EqualExcept(group, "id", "ts"); got != tt.want {
+			t.Errorf("%s (reversed): got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFindFuncSorted(t *testing.T) {
+	group := Must(ParseGroupTags("group", "e:1", "c:1", "a:1", "d:2", "b:1"))
+
+	for i := 0; i < 20; i++ {
+		var names []string
+		for _, tag := range group.FindValues("1") {
+			names = append(names, tag.Name())
+		}
+		if want := []string{"a", "b", "c", "e"}; !slices.Equal(names, want) {
+			t.Fatalf("got %q, want %q", names, want)
+		}
+	}
+}
+
+func TestContainsIgnoresValueOrder(t *testing.T) {
+	group := NewAnonymousGroup(Tag{name: "multi", values: []string{"a", "b", "c"}})
+
+	for _, values := range [][]string{{"c", "b", "a"}, {"b", "c", "a"}, {"a", "b", "c"}} {
+		if tag := (Tag{name: "multi", values: values}); !group.Contains(tag) {
+			t.Errorf("group doesn't contain %v", tag)
+		}
+	}
+	if tag := Must(NewMultiValue("multi", "c", "a", "b")); !group.Contains(tag) {
+		t.Errorf("group doesn't contain %v", tag)
+	}
+}
+
+func TestContains(t *testing.T) {
+	group := Must(ParseGroupTags("group", "multi:a,b", "label"))
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{[]string{"multi:b,a"}, true},
+		{[]string{"multi:b,a", "label"}, true},
+		{[]string{"multi:a"}, false},
+		{[]string{"multi:a,b,c"}, false},
+		{[]string{"label:a"}, false},
+		{[]string{"missing"}, false},
+	}
+	for _, tt := range tests {
+		var tags []Tag
+		for _, s := range tt.tags {
+			tags = append(tags, Must(Parse(s)))
+		}
+		if got := group.Contains(tags...); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	group := NewAnonymousGroup(
+		Tag{name: "multi", values: []string{"C", "a", "B", "b"}},
+		Must(NewLabel("label")),
+	)
+	result := group.Apply(LowercaseValues, SortValues)
+
+	if tag, _ := result.Get("multi"); !slices.Equal(tag.Values(), []string{"a", "b", "c"}) {
+		t.Errorf("got %v, want multi:a,b,c", tag)
+	}
+	if !result.ContainsLabel("label") {
+		t.Error("label missing")
+	}
+	if tag, _ := group.Get("multi"); !slices.Equal(tag.Values(), []string{"C", "a", "B", "b"}) {
+		t.Errorf("original changed to %v", tag)
+	}
+}
+
+func TestApplyNoTransforms(t *testing.T) {
+	group := Must(ParseGroupTags("group", "multi:a,b"))
+	result := group.Apply()
+	result.Add(Must(Parse("other")))
+
+	if result.Canonical() == group.Canonical() {
+		t.Error("result shares tags with the original")
+	}
+}
+
+func TestClone(t *testing.T) {
+	group := Must(ParseGroupTags("group", "multi:a,b", "label"))
+	clone := group.Clone()
+
+	if clone.Name() != group.Name() || clone.Canonical() != group.Canonical() {
+		t.Errorf("got %s %q, want %s %q", clone.Name(), clone.Canonical(), group.Name(), group.Canonical())
+	}
+
+	clone.Add(Must(Parse("other")))
+	clone.RemoveNames("label")
+	clone.SortAllValuesFunc(func(a, b string) bool { return a > b })
+	if got, want := group.Canonical(), "label\nmulti:a,b\n"; got != want {
+		t.Errorf("original changed to %q", got)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	tags := []Tag{
+		Must(Parse("app.env:prod")),
+		Must(Parse("app.region:eu")),
+		Must(Parse("db.env:dev")),
+		Must(Parse("app.env:stage")),
+		Must(Parse("label")),
+	}
+
+	byNamespace := GroupBy(tags, func(tag Tag) string {
+		ns, _, _ := strings.Cut(tag.Name(), NamespaceSeparator)
+		if ns == tag.Name() {
+			return ""
+		}
+		return ns
+	})
+	want := map[string]string{
+		"app": "app.env:prod,stage\napp.region:eu\n",
+		"db":  "db.env:dev\n",
+	}
+	if len(byNamespace) != len(want) {
+		t.Errorf("got %d groups, want %d", len(byNamespace), len(want))
+	}
+	for key, canonical := range want {
+		group, ok := byNamespace[key]
+		if !ok || group.Name() != key || group.Canonical() != canonical {
+			t.Errorf("%s: got %s %q, want %q", key, group.Name(), group.Canonical(), canonical)
+		}
+	}
+
+	byFirstLetter := GroupBy(tags, func(tag Tag) string {
+		return tag.Name()[:1]
+	})
+	if got := len(byFirstLetter); got != 3 {
+		t.Errorf("got %d groups, want 3", got)
+	}
+	if group := byFirstLetter["a"]; group.Canonical() != want["app"] {
+		t.Errorf("got %q, want %q", group.Canonical(), want["app"])
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	group := NewAnonymousGroup(
+		Tag{name: "empty", values: []string{"a", "", " "}},
+		Tag{name: "repeated", values: []string{"a", "b", "a"}},
+		Tag{name: " ", values: []string{"a"}},
+		Must(NewMultiValue("valid", "a", "b")),
+		Must(NewLabel("label")),
+	)
+
+	if changed := group.Sanitize(); changed != 3 {
+		t.Errorf("changed %d tags, want 3", changed)
+	}
+	if got, want := group.Canonical(), "empty:a\nlabel\nrepeated:a,b\nvalid:a,b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := group.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIntersectNames(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "region:eu,us", "label"))
+	other := Must(ParseGroupTags("other", "env:dev", "region:eu", "extra"))
+
+	intersection := group.IntersectNames(other)
+	if intersection.Name() != "group" {
+		t.Errorf("got name %s, want group", intersection.Name())
+	}
+	if got, want := intersection.Canonical(), "env:prod\nregion:eu,us\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	disjoint := group.IntersectNames(Must(ParseGroupTags("other", "a", "b:1")))
+	if got := disjoint.Tags(); len(got) != 0 {
+		t.Errorf("got %v, want no tags", got)
+	}
+}
+
+func TestEachIndexed(t *testing.T) {
+	group := Must(ParseGroupTags("group", "c:1", "a", "b:1,2"))
+
+	var indexes []int
+	var names []string
+	group.EachIndexed(func(i int, tag Tag) {
+		indexes = append(indexes, i)
+		names = append(names, tag.Name())
+	})
+
+	if want := []int{0, 1, 2}; !slices.Equal(indexes, want) {
+		t.Errorf("got indexes %v, want %v", indexes, want)
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(names, want) {
+		t.Errorf("got names %q, want %q", names, want)
+	}
+}
+
+func TestContainsLabel(t *testing.T) {
+	group := Must(ParseGroupTags("group", "label", "env:prod"))
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"label", true},
+		{"env", false},
+		{"missing", false},
+	}
+	for _, tt := range tests {
+		if got := group.ContainsLabel(tt.name); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAddLabelAndEmptyValueTag(t *testing.T) {
+	for _, order := range [][]string{{"env", "env:"}, {"env:", "env"}} {
+		group := Must(ParseGroupTags("group", order...))
+		if got := group.Tags(); len(got) != 1 || !got[0].IsLabel() {
+			t.Errorf("%q: got %v, want just the label env", order, got)
+		}
+	}
+
+	group := Must(ParseGroupTags("group", "env:prod", "env:"))
+	if got, want := group.Canonical(), "env\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestKeepValues(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a:x,y,z", "b:x,y", "c:x", "d:y", "label"))
+	group.KeepValues("x", "y")
+
+	if got, want := group.Canonical(), "a:x,y,z\nb:x,y\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestKeepValuesRemovesLabels(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a:x", "label"))
+	group.KeepValues()
+
+	if got, want := group.Canonical(), "a:x\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestContentID(t *testing.T) {
+	group1 := Must(ParseGroupTags("group1", "multi:a,b", "label"))
+	group2 := Must(ParseGroupTags("group2", "label", "multi:b,a"))
+
+	if group1.ContentID() != group2.ContentID() {
+		t.Error("groups with the same tags have different ContentIDs")
+	}
+	if group1.ContentIDNamed() == group2.ContentIDNamed() {
+		t.Error("groups with different names have the same ContentIDNamed")
+	}
+
+	renamed := group2.Clone()
+	_ = renamed.Rename("group1")
+	if group1.ContentIDNamed() != renamed.ContentIDNamed() {
+		t.Error("groups with the same name and tags have different ContentIDNamed")
+	}
+
+	group2.Add(Must(Parse("multi:a,b,c")))
+	if group1.ContentID() == group2.ContentID() {
+		t.Error("groups with different tags have the same ContentID")
+	}
+}
+
+// mustPanicWith fails the test if the fn doesn't panic with the err.
+func mustPanicWith(t *testing.T, name string, err error, fn func()) {
+	t.Helper()
+	defer func() {
+		t.Helper()
+		if r := recover(); r != err {
+			t.Errorf("%s: got panic %v, want %v", name, r, err)
+		}
+	}()
+	fn()
+}
+
+func TestFreeze(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "label"))
+	group.Freeze()
+
+	if !group.IsFrozen() {
+		t.Fatal("group is not frozen")
+	}
+
+	mutations := map[string]func(){
+		"Add":         func() { group.Add(Must(Parse("other"))) },
+		"Remove":      func() { group.Remove(Must(Parse("label"))) },
+		"RemoveNames": func() { group.RemoveNames("label") },
+		"RemoveFunc":  func() { group.RemoveFunc(Tag.IsLabel) },
+		"ReplaceTag":  func() { group.ReplaceTag(Must(Parse("env:dev"))) },
+		"PruneValues": func() { group.PruneValues(2) },
+		"Sanitize":    func() { group.Sanitize() },
+	}
+	for name, fn := range mutations {
+		mustPanicWith(t, name, ErrFrozen, fn)
+	}
+
+	if err := group.Rename("other"); err != ErrFrozen {
+		t.Errorf("Rename: got %v, want ErrFrozen", err)
+	}
+	if err := group.SetEnum("level", []string{"low"}, "low"); err != ErrFrozen {
+		t.Errorf("SetEnum: got %v, want ErrFrozen", err)
+	}
+	if _, err := group.ReadFrom(strings.NewReader("other\n")); err != ErrFrozen {
+		t.Errorf("ReadFrom: got %v, want ErrFrozen", err)
+	}
+
+	if group.Name() != "group" || !group.ContainsLabel("label") || group.CountNames("env") != 1 {
+		t.Error("reads of a frozen group failed")
+	}
+	if got, want := group.Canonical(), "env:prod\nlabel\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFreezeClone(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod"))
+	group.Freeze()
+
+	clone := group.Clone()
+	if clone.IsFrozen() {
+		t.Fatal("clone is frozen")
+	}
+	clone.Add(Must(Parse("label")))
+	if got, want := group.Canonical(), "env:prod\n"; got != want {
+		t.Errorf("original changed to %q", got)
+	}
+}
+
+func TestFreezeCopies(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod"))
+	copied := group
+	group.Freeze()
+
+	if !copied.IsFrozen() {
+		t.Fatal("copy is not frozen")
+	}
+	mustPanicWith(t, "Add", ErrFrozen, func() { copied.Add(Must(Parse("label"))) })
+
+	store := newTestStoreOf(t, Must(ParseGroupTags("stored", "env:prod")))
+	stored, _ := store.Group("stored")
+	stored.Freeze()
+	if again, _ := store.Group("stored"); !again.IsFrozen() {
+		t.Error("group in the store is not frozen")
+	}
+}
+
+func TestEqualFunc(t *testing.T) {
+	group := Must(ParseGroupTags("group1", "Env:Prod", "label"))
+	other := Must(ParseGroupTags("group2", "env:prod", "LABEL"))
+
+	if !group.EqualFunc(other, Tag.EqualFold) {
+		t.Error("groups differing in case are not equal with EqualFold")
+	}
+	if group.EqualFunc(other, Tag.Equal) {
+		t.Error("groups differing in case are equal with Equal")
+	}
+
+	same := Must(ParseGroupTags("group3", "label", "Env:Prod"))
+	if !group.EqualFunc(same, Tag.Equal) {
+		t.Error("groups with the same tags are not equal with Equal")
+	}
+
+	bigger := Must(ParseGroupTags("group4", "env:prod", "label", "extra"))
+	if group.EqualFunc(bigger, Tag.EqualFold) || bigger.EqualFunc(group, Tag.EqualFold) {
+		t.Error("groups with a different number of tags are equal")
+	}
+}
+
+func TestNamesWithValue(t *testing.T) {
+	group := Must(ParseGroupTags("group", "c:x", "a:x,y", "b:y", "label"))
+
+	if got, want := group.NamesWithValue("x"), []string{"a", "c"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := group.NamesWithValue("missing"); got == nil || len(got) != 0 {
+		t.Errorf("got %q, want an empty slice", got)
+	}
+}
+
+func TestAddWith(t *testing.T) {
+	keepOld := func(old, new Tag) Tag { return old }
+	union := func(old, new Tag) Tag {
+		return Must(New(old.Name(), append(old.Values(), new.Values()...)...))
+	}
+	tests := []struct {
+		name string
+		add  func(g *TagGroup, tags ...Tag)
+		want string
+	}{
+		{"replace", (*TagGroup).Add, "env:dev\nlabel\nregion:eu\n"},
+		{"keep old", func(g *TagGroup, tags ...Tag) { g.AddWith(keepOld, tags...) }, "env:prod\nlabel\nregion:eu\n"},
+		{"union", func(g *TagGroup, tags ...Tag) { g.AddWith(union, tags...) }, "env:dev,prod\nlabel\nregion:eu\n"},
+	}
+	for _, tt := range tests {
+		group := Must(ParseGroupTags("group", "env:prod", "label"))
+		tt.add(&group, Must(Parse("env:dev")), Must(Parse("region:eu")))
+
+		if got := group.Canonical(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAddWithRenamingMerge(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod"))
+	group.AddWith(func(old, new Tag) Tag {
+		return Must(New("merged."+old.Name(), append(old.Values(), new.Values()...)...))
+	}, Must(Parse("env:dev")))
+
+	if got, want := group.Canonical(), "merged.env:dev,prod\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := group.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGroupPairs(t *testing.T) {
+	group := Must(ParseGroupTags("group", "single:value", "multi:c,a,b", "label"))
+
+	want := []string{"label=", "multi=a", "multi=b", "multi=c", "single=value"}
+	if got := collectPairs(group.Pairs()); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		group, other []string
+		want         string
+	}{
+		{"addition", []string{"a:1"}, []string{"a:1", "b:2"}, "+b:2\n"},
+		{"removal", []string{"a:1", "b:2"}, []string{"a:1"}, "-b:2\n"},
+		{"value change", []string{"env:dev", "label"}, []string{"env:prod", "label"}, "-env:dev\n+env:prod\n"},
+		{"mixed", []string{"b:2,1", "c"}, []string{"a", "b:1,2,3"}, "+a\n-b:1,2\n+b:1,2,3\n-c\n"},
+		{"equal", []string{"a:2,1"}, []string{"a:1,2"}, ""},
+	}
+	for _, tt := range tests {
+		group := Must(ParseGroupTags("group", tt.group...))
+		other := Must(ParseGroupTags("other", tt.other...))
+		if got := group.Patch(other); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMergeReporting(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "region:eu", "label"))
+	other := Must(ParseGroupTags("other", "env:dev", "region:eu", "extra:x"))
+
+	merged, conflicts := group.MergeReporting(other)
+	if got, want := merged.Canonical(), "env:prod\nextra:x\nlabel\nregion:eu\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if merged.Name() != "group" {
+		t.Errorf("got name %s, want group", merged.Name())
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("got %v, want one conflict", conflicts)
+	}
+	conflict := conflicts[0]
+	if conflict.Name != "env" || !conflict.Tag.Equal(Must(Parse("env:prod"))) ||
+		!conflict.Other.Equal(Must(Parse("env:dev"))) {
+		t.Errorf("got %+v, want env:prod vs env:dev", conflict)
+	}
+	if got, want := group.Canonical(), "env:prod\nlabel\nregion:eu\n"; got != want {
+		t.Errorf("original changed to %q", got)
+	}
+}
+
+func TestMergeReportingClean(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod"))
+	other := Must(ParseGroupTags("other", "env:prod", "label"))
+
+	merged, conflicts := group.MergeReporting(other)
+	if len(conflicts) != 0 {
+		t.Errorf("got %v, want no conflicts", conflicts)
+	}
+	if got, want := merged.Canonical(), "env:prod\nlabel\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewAnonymousGroup(t *testing.T) {
+	group := NewAnonymousGroup(Must(Parse("env:prod")))
+	if group.Name() != "" {
+		t.Errorf("got name %q, want an empty name", group.Name())
+	}
+
+	group.Add(Must(Parse("label")))
+	if got, want := group.Canonical(), "env:prod\nlabel\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	data, err := json.Marshal(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"tags":[{"name":"env","values":["prod"]},{"name":"label"}]}`; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	if err := group.Rename("named"); err != nil {
+		t.Fatal(err)
+	}
+	if group.Name() != "named" {
+		t.Errorf("got name %q, want named", group.Name())
+	}
+}
+
+func TestAddValueToMatching(t *testing.T) {
+	group := Must(ParseGroupTags("group", "status:open", "status2:open,reviewed", "other:open", "label"))
+	changed, err := group.AddValueToMatching(func(tag Tag) bool {
+		return strings.HasPrefix(tag.Name(), "status")
+	}, "reviewed", "", "reviewed")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if changed != 1 {
+		t.Errorf("changed %d tags, want 1", changed)
+	}
+	want := "label\nother:open\nstatus:open,reviewed\nstatus2:open,reviewed\n"
+	if got := group.Canonical(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if tag, _ := group.Get("status2"); len(tag.Values()) != 2 {
+		t.Errorf("got %v, want two values", tag)
+	}
+}
+
+func TestAddValueToMatchingKeepsNames(t *testing.T) {
+	group := Must(ParseGroupTags("group", "Env:prod", "label"))
+	setCase(t, CaseLower)
+
+	if changed, err := group.AddValueToMatching(Tag.IsSingleValue, "Dev"); err != nil || changed != 1 {
+		t.Errorf("changed %d tags (%v), want 1", changed, err)
+	}
+	if got, want := group.Canonical(), "Env:dev,prod\nlabel\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := group.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	group.AddWith(combineValues, Tag{name: "Env", values: []string{"stage"}})
+	if got, want := group.Canonical(), "Env:dev,prod,stage\nlabel\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := group.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFindFirst(t *testing.T) {
+	group := Must(ParseGroupTags("group", "c:x", "b:x", "d:y", "label"))
+	tests := []struct {
+		name  string
+		value string
+		want  string
+		found bool
+	}{
+		{"multiple matches", "x", "b", true},
+		{"single match", "y", "d", true},
+		{"no match", "z", "", false},
+	}
+	for _, tt := range tests {
+		tag, found := group.FindFirst(func(tag Tag) bool {
+			return tag.HasValues(tt.value)
+		})
+		if found != tt.found || tag.Name() != tt.want {
+			t.Errorf("%s: got %v, %v, want %s, %v", tt.name, tag, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestCountValue(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a:eu,us", "b:eu", "c:us", "label"))
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{"eu", 2},
+		{"us", 2},
+		{"asia", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := group.CountValue(tt.value); got != tt.want {
+			t.Errorf("%q: got %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestCloneAs(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "label"))
+	clone, err := group.CloneAs("fork")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clone.Name() != "fork" || clone.Canonical() != group.Canonical() {
+		t.Errorf("got %s %q", clone.Name(), clone.Canonical())
+	}
+	clone.Add(Must(Parse("env:dev")))
+	clone.RemoveNames("label")
+	if group.Name() != "group" || group.Canonical() != "env:prod\nlabel\n" {
+		t.Errorf("original changed to %s %q", group.Name(), group.Canonical())
+	}
+
+	if _, err := group.CloneAs(""); err == nil {
+		t.Error("expected an error for an empty name")
+	}
+}
+
+func TestFindConflicting(t *testing.T) {
+	group := Must(ParseGroupTags("group",
+		"status:open,closed",
+		"status2:open,reviewed",
+		"size:small,large,open",
+		"label",
+	))
+	exclusive := [][]string{{"open", "closed"}, {"small", "medium", "large"}}
+
+	var names []string
+	for _, tag := range group.FindConflicting(exclusive) {
+		names = append(names, tag.Name())
+	}
+	if want := []string{"size", "status"}; !slices.Equal(names, want) {
+		t.Errorf("got %q, want %q", names, want)
+	}
+
+	if got := group.FindConflicting(nil); len(got) != 0 {
+		t.Errorf("got %v without exclusive sets", got)
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	group := Must(ParseGroupTags("group1", "Env:Prod,dev", "LABEL"))
+	tests := []struct {
+		name  string
+		other []string
+		want  bool
+	}{
+		{"case and order", []string{"env:DEV,prod", "label"}, true},
+		{"different value", []string{"env:prod,stage", "label"}, false},
+		{"missing tag", []string{"env:prod,dev"}, false},
+		{"extra tag", []string{"env:prod,dev", "label", "extra"}, false},
+	}
+	for _, tt := range tests {
+		other := Must(ParseGroupTags("group2", tt.other...))
+		if got := group.Equivalent(other); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFindCaseCollisions(t *testing.T) {
+	group := Must(ParseGroupTags("group", "Env:prod", "env:dev", "ID", "id", "Id:1", "label"))
+
+	var got [][]string
+	for _, collision := range group.FindCaseCollisions() {
+		var names []string
+		for _, tag := range collision {
+			names = append(names, tag.Name())
+		}
+		got = append(got, names)
+	}
+	want := [][]string{{"Env", "env"}, {"ID", "Id", "id"}}
+	if !slices.EqualFunc(got, want, slices.Equal[string]) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	noCollisions := Must(ParseGroupTags("group", "env:prod", "label"))
+	if got := noCollisions.FindCaseCollisions(); len(got) != 0 {
+		t.Errorf("got %v, want no collisions", got)
+	}
+}
+
+func TestCloneKeepsEmptyValue(t *testing.T) {
+	group := Must(NewGroup("group"))
+	group.Add(Must(ParseVerbose("label:")))
+
+	cloneAs := Must(group.CloneAs("other"))
+	merged, _ := group.MergeReporting(NewAnonymousGroup())
+	tests := []struct {
+		name  string
+		group TagGroup
+	}{
+		{"Clone", group.Clone()},
+		{"CloneAs", cloneAs},
+		{"Apply", group.Apply(LowercaseValues, SortValues)},
+		{"MergeReporting", merged},
+	}
+	for _, tt := range tests {
+		if tag, _ := tt.group.Get("label"); !tag.IsEmptyValue() {
+			t.Errorf("%s: the empty value was lost", tt.name)
+		}
+	}
+}
+
+func TestNewGroupMerging(t *testing.T) {
+	var tags []Tag
+	for i := 0; i < 100; i++ {
+		tags = append(tags, Must(New("name"+strconv.Itoa(i%7), "value"+strconv.Itoa(i%5))))
+	}
+	tags = append(tags, Must(Parse("label")), Must(Parse("name1:other,value1")))
+
+	naive := Must(NewGroup("group"))
+	naive.AddWith(combineValues, tags...)
+
+	merged, err := NewGroupMerging("group", tags...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := merged.Canonical(), naive.Canonical(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := merged.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewGroupMergingCase(t *testing.T) {
+	setCase(t, CaseLower)
+
+	tags := []Tag{{name: "Env", values: []string{"a"}}, {name: "env", values: []string{"b"}}, {name: "ENV"}}
+	group, err := NewGroupMerging("group", tags...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := group.Canonical(), "env:a,b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := group.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewGroupMergingErrors(t *testing.T) {
+	if _, err := NewGroupMerging("", Must(Parse("label"))); err == nil {
+		t.Error("expected an error for an empty group name")
+	}
+	if _, err := NewGroupMerging("group", Must(Parse("label")), Tag{}); err == nil {
+		t.Error("expected an error for a zero tag")
+	}
+
+	setValueValidator(t, rejectSpaces)
+	tag := Tag{name: "multi", values: []string{"a", "b c"}}
+	if _, err := NewGroupMerging("group", tag); err == nil {
+		t.Error("expected an error for an invalid value")
+	}
+}
+
+func BenchmarkNewGroupMerging(b *testing.B) {
+	tags := make([]Tag, 0, 100_000)
+	for i := 0; i < cap(tags); i++ {
+		tags = append(tags, Must(New("name"+strconv.Itoa(i%1000), "value"+strconv.Itoa(i%10))))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = NewGroupMerging("group", tags...)
+	}
+}
+
+func TestValueValidatorGroups(t *testing.T) {
+	group := Must(ParseGroupTags("group", "multi:a,b", "label"))
+	setValueValidator(t, rejectSpaces)
+
+	group.AddWith(combineValues, Tag{name: "multi", values: []string{"c d"}})
+	if got, want := group.Canonical(), "label\nmulti:a,b,c d\n"; got != want {
+		t.Errorf("AddWith: got %q, want %q", got, want)
+	}
+
+	remapped, err := group.Remap(map[string]string{"multi": "other"}, map[string]string{"c d": "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := remapped.Canonical(), "label\nother:a,b,c\n"; got != want {
+		t.Errorf("Remap: got %q, want %q", got, want)
+	}
+	if _, err := group.Remap(map[string]string{"multi": "other"}, map[string]string{"a": "x y"}); err == nil {
+		t.Error("Remap: expected an error for an invalid value")
+	}
+
+	if _, err := group.AddValueToMatching(Tag.IsMultiValue, "x y"); err == nil {
+		t.Error("AddValueToMatching: expected an error for an invalid value")
+	}
+	if changed, err := group.AddValueToMatching(Tag.IsLabel, "x"); err != nil || changed != 1 {
+		t.Errorf("AddValueToMatching: changed %d tags (%v), want 1", changed, err)
+	}
+	if got, want := group.Canonical(), "label:x\nmulti:a,b,c d\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	groups := GroupBy([]Tag{{name: "multi", values: []string{"a b"}}, {name: "multi", values: []string{"c"}}}, func(Tag) string {
+		return "group"
+	})
+	grouped := groups["group"]
+	if got, want := grouped.Canonical(), "multi:a b,c\n"; got != want {
+		t.Errorf("GroupBy: got %q, want %q", got, want)
+	}
+}
+
+func TestSample(t *testing.T) {
+	group := Must(ParseGroupTags("group", "a", "b", "c", "d", "e", "f"))
+
+	sample1 := group.Sample(3, rand.New(rand.NewSource(42)))
+	sample2 := group.Sample(3, rand.New(rand.NewSource(42)))
+	if len(sample1) != 3 {
+		t.Errorf("got %d tags, want 3", len(sample1))
+	}
+	if !slices.EqualFunc(sample1, sample2, Tag.Equal) {
+		t.Errorf("got %v and %v for the same seed", sample1, sample2)
+	}
+	if !group.Contains(sample1...) {
+		t.Errorf("sample %v is not from the group", sample1)
+	}
+	if names := tagNames(sample1); len(slices.Compact(names)) != 3 {
+		t.Errorf("got repeating tags %q", names)
+	}
+
+	for _, n := range []int{6, 10} {
+		if got := group.Sample(n, rand.New(rand.NewSource(42))); len(got) != 6 {
+			t.Errorf("n %d: got %d tags, want 6", n, len(got))
+		}
+	}
+	for _, n := range []int{0, -1} {
+		if got := group.Sample(n, rand.New(rand.NewSource(42))); len(got) != 0 {
+			t.Errorf("n %d: got %v, want no tags", n, got)
+		}
+	}
+}
+
+func TestContainsByName(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "label"))
+
+	tests := []struct {
+		tags   []string
+		strict bool
+		byName bool
+	}{
+		{[]string{"env"}, false, true},
+		{[]string{"env:dev"}, false, true},
+		{[]string{"env:prod"}, true, true},
+		{[]string{"label:value"}, false, true},
+		{[]string{"env:prod", "label"}, true, true},
+		{[]string{"env", "other"}, false, false},
+		{[]string{"other"}, false, false},
+	}
+	for _, tt := range tests {
+		var tags []Tag
+		for _, s := range tt.tags {
+			tags = append(tags, Must(Parse(s)))
+		}
+		if got := group.Contains(tags...); got != tt.strict {
+			t.Errorf("Contains(%q): got %v, want %v", tt.tags, got, tt.strict)
+		}
+		if got := group.ContainsByName(tags...); got != tt.byName {
+			t.Errorf("ContainsByName(%q): got %v, want %v", tt.tags, got, tt.byName)
+		}
+	}
+}
+
+func TestEqualOn(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "region:eu,us", "owner:alice"))
+	other := Must(ParseGroupTags("other", "env:prod", "region:us,eu", "owner:bob", "extra"))
+
+	tests := []struct {
+		names []string
+		want  bool
+	}{
+		{[]string{"env", "region"}, true},
+		{[]string{"env", "owner"}, false},
+		{[]string{"extra"}, false},
+		{[]string{"env", "missing"}, true},
+		{nil, true},
+	}
+	for _, tt := range tests {
+		if got := group.EqualOn(other, tt.names...); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.names, got, tt.want)
+		}
+		if got := other.EqualOn(group, tt.names...); got != tt.want {
+			t.Errorf("%q reversed: got %v, want %v", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestNamespaced(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "multi:a,b", "label"))
+
+	namespaced, err := group.Namespaced("app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespaced.Name() != "group" {
+		t.Errorf("got %s, want group", namespaced.Name())
+	}
+	if got, want := namespaced.Canonical(), "app.env:prod\napp.label\napp.multi:a,b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if tag, ok := namespaced.Get("app.env"); !ok || tag.Value() != "prod" {
+		t.Errorf("got %v, %v, want app.env:prod", tag, ok)
+	}
+	if err := namespaced.Validate(); err != nil {
+		t.Error(err)
+	}
+	if got, want := group.Canonical(), "env:prod\nlabel\nmulti:a,b\n"; got != want {
+		t.Errorf("original changed to %q", got)
+	}
+}
+
+func TestWriteToReadFrom(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "multi:b,a", "label"))
+
+	r, w := io.Pipe()
+	written := make(chan int64, 1)
+	go func() {
+		n, err := group.WriteTo(w)
+		w.CloseWithError(err)
+		written <- n
+	}()
+
+	copied := Must(NewGroup("copy"))
+	read, err := copied.ReadFrom(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := int64(len(group.Canonical()))
+	if n := <-written; n != want {
+		t.Errorf("wrote %d bytes, want %d", n, want)
+	}
+	if read != want {
+		t.Errorf("read %d bytes, want %d", read, want)
+	}
+	if copied.Canonical() != group.Canonical() {
+		t.Errorf("got %q, want %q", copied.Canonical(), group.Canonical())
+	}
+}
+
+func TestReadFromZeroValue(t *testing.T) {
+	var group TagGroup
+	n, err := group.ReadFrom(strings.NewReader("env:prod\nlabel\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 15 {
+		t.Errorf("read %d bytes, want 15", n)
+	}
+	if got, want := group.Canonical(), "env:prod\nlabel\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	group.Freeze()
+	if !group.IsFrozen() {
+		t.Error("group not frozen")
+	}
+}
+
+func TestReadFromInvalidLine(t *testing.T) {
+	group := Must(ParseGroupTags("group", "label"))
+
+	_, err := group.ReadFrom(strings.NewReader("env:prod\n\n:value\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("got %v, want an error for line 3", err)
+	}
+	if got, want := group.Canonical(), "label\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSetEnum(t *testing.T) {
+	allowed := []string{"low", "medium", "high"}
+	group := Must(ParseGroupTags("group", "label"))
+
+	if err := group.SetEnum("severity", allowed, "low"); err != nil {
+		t.Fatal(err)
+	}
+	if err := group.SetEnum("severity", allowed, "high"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := group.Canonical(), "label\nseverity:high\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if err := group.SetEnum("severity", allowed, "critical"); err == nil {
+		t.Error("expected an error for a value not allowed")
+	}
+	if got, want := group.Canonical(), "label\nseverity:high\n"; got != want {
+		t.Errorf("got %q after an invalid value, want %q", got, want)
+	}
+
+	group.Freeze()
+	if err := group.SetEnum("severity", allowed, "low"); err != ErrFrozen {
+		t.Errorf("got %v, want ErrFrozen", err)
+	}
+}
+
+func TestTopNames(t *testing.T) {
+	group := Must(ParseGroupTags("group", "b:1,2", "label", "a:1,2", "c:1,2,3", "single:1"))
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{1, []string{"c"}},
+		{3, []string{"c", "a", "b"}},
+		{5, []string{"c", "a", "b", "single", "label"}},
+		{10, []string{"c", "a", "b", "single", "label"}},
+		{0, []string{}},
+	}
+	for _, tt := range tests {
+		if got := group.TopNames(tt.n); !slices.Equal(got, tt.want) {
+			t.Errorf("n %d: got %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestReplaceTag(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "label"))
+
+	if replaced := group.ReplaceTag(Must(Parse("env:dev,stage"))); !replaced {
+		t.Error("existing tag env not replaced")
+	}
+	if replaced := group.ReplaceTag(Must(Parse("region:eu"))); replaced {
+		t.Error("new tag region reported as replaced")
+	}
+	if got, want := group.Canonical(), "env:dev,stage\nlabel\nregion:eu\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	group.Freeze()
+	mustPanicWith(t, "ReplaceTag", ErrFrozen, func() {
+		group.ReplaceTag(Must(Parse("label")))
+	})
+}
+
+func TestRemoveIgnoresValueOrder(t *testing.T) {
+	group := NewAnonymousGroup(
+		Tag{name: "multi", values: []string{"a", "b", "c"}},
+		Tag{name: "other", values: []string{"x", "y"}},
+		Tag{name: "label"},
+	)
+
+	group.Remove(Tag{name: "multi", values: []string{"c", "a", "b"}}, Tag{name: "other", values: []string{"y"}})
+	if got, want := group.Canonical(), "label\nother:x,y\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	group.Remove(Must(Parse("other:y,x")), Must(Parse("label")), Must(Parse("missing")))
+	if got := group.Canonical(); got != "" {
+		t.Errorf("got %q, want an empty group", got)
+	}
+}
+
+func TestParseGroupTags(t *testing.T) {
+	group, err := ParseGroupTags("group", "env:prod", "multi:a,b", "label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.Name() != "group" {
+		t.Errorf("got %s, want group", group.Name())
+	}
+	if got, want := group.Canonical(), "env:prod\nlabel\nmulti:a,b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	duplicates := Must(ParseGroupTags("group", "env:prod", "env:dev"))
+	if got, want := duplicates.Canonical(), "env:dev\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseGroupTagsErrors(t *testing.T) {
+	_, err := ParseGroupTags("group", "env:prod", ":value")
+	if err == nil || !strings.Contains(err.Error(), "':value'") {
+		t.Errorf("got %v, want an error with the tag string", err)
+	}
+
+	if _, err := ParseGroupTags("", "env:prod"); err == nil {
+		t.Error("expected an error for an empty group name")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	group := Must(ParseGroupTags("group", "env:prod", "multi:a,b", "label"))
+	if err := group.Validate(); err != nil {
+		t.Errorf("got %v for a valid group", err)
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		tag  Tag
+		want string
+	}{
+		{"key mismatch", "other", Tag{name: "env", values: []string{"prod"}}, "tag 'env' stored as 'other'"},
+		{"empty name", " ", Tag{name: " "}, "tag ' ': name required"},
+		{"empty value", "multi", Tag{name: "multi", values: []string{"a", " "}}, "tag 'multi': empty value"},
+		{"repeating value", "multi", Tag{name: "multi", values: []string{"a", "b", "a"}}, "tag 'multi': repeating value 'a'"},
+	}
+	for _, tt := range tests {
+		group := Must(ParseGroupTags("group", "env:prod", "multi:a,b", "label"))
+		group.tags[tt.key] = tt.tag
+
+		err := group.Validate()
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package tags
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// TagStore is a collection of groups with unique names.
+type TagStore struct {
+	groups map[string]TagGroup
+}
+
+// Groups returns the store groups sorted by name.
+//
+// Groups can be added to the store with the [TagStore.Add] method.
+func (s *TagStore) Groups() []TagGroup {
+	groups := maps.Values(s.groups)
+	slices.SortFunc(groups, func(group1, group2 TagGroup) bool {
+		return group1.name < group2.name
+	})
+	return groups
+}
+
+// Group returns the group with the name and true, or an empty group and false
+// if the store doesn't contain such group.
+func (s *TagStore) Group(name string) (TagGroup, bool) {
+	group, ok := s.groups[name]
+	return group, ok
+}
+
+// Add adds groups to the store.
+//
+// The group names must be unique, i.e. it returns an error if the store
+// already contains a group with the same name. In that case no group is added.
+func (s *TagStore) Add(groups ...TagGroup) error {
+	names := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		if _, ok := s.groups[g.name]; ok || names[g.name] {
+			return fmt.Errorf("group already exists: '%s'", g.name)
+		}
+		names[g.name] = true
+	}
+
+	for _, g := range groups {
+		s.groups[g.name] = g
+	}
+	return nil
+}
+
+// RenameGroup renames the group with the oldName to the newName.
+//
+// It returns an error if the store doesn't contain a group with the oldName,
+// if it already contains another group with the newName or if the newName is
+// empty. Renaming a group to its own name does nothing.
+func (s *TagStore) RenameGroup(oldName, newName string) error {
+	group, ok := s.groups[oldName]
+	if !ok {
+		return fmt.Errorf("group not found: '%s'", oldName)
+	}
+	if newName == oldName {
+		return nil
+	}
+	if _, ok := s.groups[newName]; ok {
+		return fmt.Errorf("group already exists: '%s'", newName)
+	}
+
+	err := group.Rename(newName)
+	if err != nil {
+		return err
+	}
+
+	delete(s.groups, oldName)
+	s.groups[newName] = group
+	return nil
+}
+
+// RenameGroups renames the groups according to the mapping of old names to new
+// names.
+//
+// Either all groups are renamed or none, i.e. if any group cannot be renamed
+// (see the [TagStore.RenameGroup] method docs) or two groups would get
+// the same name, it returns an error and the store is not changed. Groups can
+// swap their names.
+func (s *TagStore) RenameGroups(mapping map[string]string) error {
+	oldNames := maps.Keys(mapping)
+	slices.Sort(oldNames)
+
+	renamed := make(map[string]TagGroup, len(mapping))
+	for _, oldName := range oldNames {
+		newName := mapping[oldName]
+		group, ok := s.groups[oldName]
+		if !ok {
+			return fmt.Errorf("group not found: '%s'", oldName)
+		}
+
+		_, taken := s.groups[newName]
+		_, renamedAway := mapping[newName]
+		_, renamedTo := renamed[newName]
+		if (taken && !renamedAway) || renamedTo {
+			return fmt.Errorf("group already exists: '%s'", newName)
+		}
+
+		err := group.Rename(newName)
+		if err != nil {
+			return err
+		}
+		renamed[newName] = group
+	}
+
+	for _, oldName := range oldNames {
+		delete(s.groups, oldName)
+	}
+	maps.Copy(s.groups, renamed)
+	return nil
+}
+
+// ContainsValue returns true if any store group has a tag with the value.
+func (s *TagStore) ContainsValue(value string) bool {
+	for _, g := range s.groups {
+		if g.CountValue(value) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupsWithValue returns the groups that have a tag with the value sorted by
+// name. If there are no such groups, it returns an empty slice.
+func (s *TagStore) GroupsWithValue(value string) []TagGroup {
+	groups := []TagGroup{}
+	for _, g := range s.Groups() {
+		if g.CountValue(value) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// NameCounts returns the number of tags with each name across all the store
+// groups.
+func (s *TagStore) NameCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, g := range s.groups {
+		for name := range g.tags {
+			counts[name]++
+		}
+	}
+	return counts
+}
+
+// ValueCounts returns the number of tags with each value across all the store
+// groups.
+func (s *TagStore) ValueCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, g := range s.groups {
+		for _, t := range g.tags {
+			for _, v := range t.values {
+				counts[v]++
+			}
+		}
+	}
+	return counts
+}
+
+// ValueCooccurrence returns for each pair of values the number of groups in
+// which the values appear together in a single tag. The values in a pair are
+// sorted, so each pair is counted under one key regardless of the order.
+//
+// Labels and single value tags don't contribute any pairs.
+func (s *TagStore) ValueCooccurrence() map[[2]string]int {
+	counts := make(map[[2]string]int)
+	for _, g := range s.groups {
+		pairs := make(map[[2]string]bool)
+		for _, t := range g.tags {
+			values := t.SortedUniqueValues()
+			for i := range values {
+				for j := i + 1; j < len(values); j++ {
+					pairs[[2]string{values[i], values[j]}] = true
+				}
+			}
+		}
+
+		for pair := range pairs {
+			counts[pair]++
+		}
+	}
+	return counts
+}
+
+// WriteCanonical writes the canonical string representation of the store to
+// the w. The groups are sorted by name and each one is written as a header
+// line with the group name prefixed with '@' followed by the canonical string
+// representation of the group tags (see the [TagGroup.Canonical] method).
+//
+// The groups are written one by one without building the whole output in
+// memory. The output can be read with the [ParseDocument] function, so it
+// returns an error without writing anything if the store contains an anonymous
+// group (see the [NewAnonymousGroup] function) or a tag whose name starts with
+// '@'.
+func (s *TagStore) WriteCanonical(w io.Writer) error {
+	groups := s.Groups()
+	for _, g := range groups {
+		if g.name == "" {
+			return fmt.Errorf("group name required")
+		}
+		for name := range g.tags {
+			if strings.HasPrefix(strings.TrimSpace(name), groupMarker) {
+				return fmt.Errorf("invalid tag name in group '%s': '%s' (cannot start with '%s')",
+					g.name, name, groupMarker)
+			}
+		}
+	}
+
+	for _, g := range groups {
+		_, err := io.WriteString(w, groupMarker+g.name+"\n")
+		if err != nil {
+			return err
+		}
+
+		err = g.writeCanonical(w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewStore creates a store and adds the provided groups to it.
+//
+// The group names must be unique, see the [TagStore.Add] method docs.
+func NewStore(groups ...TagGroup) (TagStore, error) {
+	store := TagStore{groups: map[string]TagGroup{}}
+	err := store.Add(groups...)
+	if err != nil {
+		return TagStore{}, err
+	}
+	return store, nil
+}
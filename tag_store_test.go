@@ -0,0 +1,261 @@
+package tags
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+func newTestStore(t *testing.T, names ...string) TagStore {
+	t.Helper()
+	var groups []TagGroup
+	for _, name := range names {
+		groups = append(groups, Must(NewGroup(name)))
+	}
+	return newTestStoreOf(t, groups...)
+}
+
+func newTestStoreOf(t *testing.T, groups ...TagGroup) TagStore {
+	t.Helper()
+	store, err := NewStore(groups...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestRenameGroup(t *testing.T) {
+	store := newTestStore(t, "a", "b")
+	err := store.RenameGroup("a", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := store.Group("a"); ok {
+		t.Error("group a still exists")
+	}
+	group, ok := store.Group("c")
+	if !ok || group.Name() != "c" {
+		t.Errorf("got %v, %v, want group c", group.Name(), ok)
+	}
+}
+
+func TestRenameGroupErrors(t *testing.T) {
+	tests := []struct {
+		name             string
+		oldName, newName string
+	}{
+		{"collision", "a", "b"},
+		{"missing", "x", "c"},
+		{"empty name", "a", ""},
+	}
+	for _, tt := range tests {
+		store := newTestStore(t, "a", "b")
+		if err := store.RenameGroup(tt.oldName, tt.newName); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+		if got := len(store.Groups()); got != 2 {
+			t.Errorf("%s: got %d groups, want 2", tt.name, got)
+		}
+		for _, name := range []string{"a", "b"} {
+			if _, ok := store.Group(name); !ok {
+				t.Errorf("%s: group %s missing", tt.name, name)
+			}
+		}
+	}
+}
+
+func TestRenameGroupToSameName(t *testing.T) {
+	store := newTestStore(t, "a", "b")
+	if err := store.RenameGroup("a", "a"); err != nil {
+		t.Errorf("RenameGroup: %v", err)
+	}
+	if _, ok := store.Group("a"); !ok {
+		t.Error("group a missing")
+	}
+}
+
+func TestNameAndValueCounts(t *testing.T) {
+	store := newTestStoreOf(t,
+		Must(ParseGroupTags("a", "env:prod", "region:eu,us", "label")),
+		Must(ParseGroupTags("b", "env:prod", "region:eu")),
+		Must(ParseGroupTags("c", "env:dev")),
+	)
+
+	wantNames := map[string]int{"env": 3, "region": 2, "label": 1}
+	if got := store.NameCounts(); !maps.Equal(got, wantNames) {
+		t.Errorf("NameCounts: got %v, want %v", got, wantNames)
+	}
+	wantValues := map[string]int{"prod": 2, "dev": 1, "eu": 2, "us": 1}
+	if got := store.ValueCounts(); !maps.Equal(got, wantValues) {
+		t.Errorf("ValueCounts: got %v, want %v", got, wantValues)
+	}
+}
+
+func TestWriteCanonical(t *testing.T) {
+	store := newTestStoreOf(t,
+		Must(ParseGroupTags("server", "regions:us,eu", "env:prod")),
+		Must(ParseGroupTags("client", "debug")),
+		Must(NewGroup("empty")),
+	)
+
+	want := "@client\ndebug\n@empty\n@server\nenv:prod\nregions:eu,us\n"
+	for i := 0; i < 10; i++ {
+		var b strings.Builder
+		if err := store.WriteCanonical(&b); err != nil {
+			t.Fatal(err)
+		}
+		if got := b.String(); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+
+	groups, err := ParseDocument(strings.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 3 || groups[2].Canonical() != "env:prod\nregions:eu,us\n" {
+		t.Errorf("got %v", groups)
+	}
+}
+
+func TestWriteCanonicalErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		group TagGroup
+	}{
+		{"anonymous group", NewAnonymousGroup(Must(Parse("env:prod")))},
+		{"tag name with the group marker", Must(ParseGroupTags("group", "@env:prod"))},
+	}
+	for _, tt := range tests {
+		store := newTestStoreOf(t, Must(ParseGroupTags("valid", "label")), tt.group)
+
+		var b strings.Builder
+		if err := store.WriteCanonical(&b); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+		if b.Len() != 0 {
+			t.Errorf("%s: got output %q", tt.name, b.String())
+		}
+	}
+}
+
+// groupNames returns the sorted names of the store groups.
+func groupNames(s *TagStore) []string {
+	var names []string
+	for _, g := range s.Groups() {
+		names = append(names, g.Name())
+	}
+	return names
+}
+
+func TestRenameGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		want    []string
+	}{
+		{"batch", map[string]string{"a": "x", "b": "y"}, []string{"c", "x", "y"}},
+		{"swap", map[string]string{"a": "b", "b": "a"}, []string{"a", "b", "c"}},
+		{"same name", map[string]string{"a": "a"}, []string{"a", "b", "c"}},
+		{"chain", map[string]string{"a": "b", "b": "d"}, []string{"b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		store := newTestStore(t, "a", "b", "c")
+		if err := store.RenameGroups(tt.mapping); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := groupNames(&store); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		for _, g := range store.Groups() {
+			if stored, _ := store.Group(g.Name()); stored.Name() != g.Name() {
+				t.Errorf("%s: group %s stored as %s", tt.name, stored.Name(), g.Name())
+			}
+		}
+	}
+}
+
+func TestRenameGroupsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping map[string]string
+	}{
+		{"collision", map[string]string{"a": "x", "b": "c"}},
+		{"same target", map[string]string{"a": "x", "b": "x"}},
+		{"missing source", map[string]string{"a": "x", "missing": "y"}},
+		{"empty name", map[string]string{"a": "x", "b": ""}},
+	}
+	for _, tt := range tests {
+		store := newTestStore(t, "a", "b", "c")
+		if err := store.RenameGroups(tt.mapping); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+		if got, want := groupNames(&store), []string{"a", "b", "c"}; !slices.Equal(got, want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, want)
+		}
+		for _, name := range []string{"a", "b", "c"} {
+			if g, _ := store.Group(name); g.Name() != name {
+				t.Errorf("%s: group %s renamed to %s", tt.name, name, g.Name())
+			}
+		}
+	}
+}
+
+func TestValueCooccurrence(t *testing.T) {
+	store := newTestStoreOf(t,
+		Must(ParseGroupTags("a", "colors:red,blue", "sizes:s,m", "label", "single:red")),
+		Must(ParseGroupTags("b", "colors:blue,red,green", "other:red,blue")),
+		Must(ParseGroupTags("c", "single:red", "other:blue", "label")),
+	)
+
+	want := map[[2]string]int{
+		{"blue", "red"}:   2,
+		{"blue", "green"}: 1,
+		{"green", "red"}:  1,
+		{"m", "s"}:        1,
+	}
+	if got := store.ValueCooccurrence(); !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	single := newTestStoreOf(t, Must(ParseGroupTags("a", "label", "single:red", "other:blue")))
+	if got := single.ValueCooccurrence(); len(got) != 0 {
+		t.Errorf("got %v, want no pairs", got)
+	}
+}
+
+func TestContainsValue(t *testing.T) {
+	store := newTestStoreOf(t,
+		Must(ParseGroupTags("c", "env:prod,stage")),
+		Must(ParseGroupTags("a", "env:prod", "label")),
+		Must(ParseGroupTags("b", "env:dev", "prod")),
+	)
+
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"prod", []string{"a", "c"}},
+		{"dev", []string{"b"}},
+		{"missing", []string{}},
+		{"label", []string{}},
+	}
+	for _, tt := range tests {
+		if got := store.ContainsValue(tt.value); got != (len(tt.want) > 0) {
+			t.Errorf("ContainsValue(%s): got %v", tt.value, got)
+		}
+
+		groups := store.GroupsWithValue(tt.value)
+		names := []string{}
+		for _, g := range groups {
+			names = append(names, g.Name())
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("GroupsWithValue(%s): got %q, want %q", tt.value, names, tt.want)
+		}
+	}
+}
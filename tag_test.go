@@ -0,0 +1,1006 @@
+package tags
+
+import (
+	"errors"
+	"iter"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestSubtractValues(t *testing.T) {
+	tests := []struct {
+		tag, other, want string
+	}{
+		{"multi:a,b", "multi:a,b,c", "multi"},
+		{"multi:a,b", "multi:c,d", "multi:a,b"},
+		{"multi:a,b,c", "multi:b", "multi:a,c"},
+		{"multi:a,b", "other:a,b", "multi:a,b"},
+	}
+	for _, tt := range tests {
+		got := Must(Parse(tt.tag)).SubtractValues(Must(Parse(tt.other)))
+		if want := Must(Parse(tt.want)); !got.Equal(want) {
+			t.Errorf("%s - %s: got %v, want %v", tt.tag, tt.other, got, want)
+		}
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		tag1, tag2 string
+		want       bool
+	}{
+		{"label", "label", true},
+		{"multi:a,b", "multi:b,a", true},
+		{"multi:a,b", "multi:a", false},
+		{"multi:a,b", "other:a,b", false},
+		{"label", "label:a", false},
+	}
+	for _, tt := range tests {
+		if got := Must(Parse(tt.tag1)).Equal(Must(Parse(tt.tag2))); got != tt.want {
+			t.Errorf("%s == %s: got %v, want %v", tt.tag1, tt.tag2, got, tt.want)
+		}
+	}
+}
+
+func TestEqualRepeatingValues(t *testing.T) {
+	tag1 := Tag{name: "x", values: []string{"a", "a"}}
+	tag2 := Tag{name: "x", values: []string{"a", "b"}}
+
+	if tag1.Equal(tag2) || tag2.Equal(tag1) {
+		t.Errorf("%#v and %#v are equal", tag1, tag2)
+	}
+	if tag1.Compare(tag2) == 0 || tag2.Compare(tag1) == 0 {
+		t.Errorf("%#v and %#v compare as equal", tag1, tag2)
+	}
+	if tag1.Compare(tag2) != -tag2.Compare(tag1) {
+		t.Errorf("Compare is not antisymmetric for %#v and %#v", tag1, tag2)
+	}
+}
+
+func TestEqualFold(t *testing.T) {
+	tests := []struct {
+		tag1, tag2 string
+		equal      bool
+		equalFold  bool
+	}{
+		{"Env:prod", "env:prod", false, true},
+		{"env:PROD", "env:prod", false, true},
+		{"ENV:a,B", "env:b,A", false, true},
+		{"env:prod", "env:prod", true, true},
+		{"env:prod", "env:dev", false, false},
+		{"env:prod", "env:prod,dev", false, false},
+	}
+	for _, tt := range tests {
+		tag1, tag2 := Must(Parse(tt.tag1)), Must(Parse(tt.tag2))
+		if got := tag1.Equal(tag2); got != tt.equal {
+			t.Errorf("%s Equal %s: got %v, want %v", tt.tag1, tt.tag2, got, tt.equal)
+		}
+		if got := tag1.EqualFold(tag2); got != tt.equalFold {
+			t.Errorf("%s EqualFold %s: got %v, want %v", tt.tag1, tt.tag2, got, tt.equalFold)
+		}
+	}
+}
+
+func TestParseHashtag(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"#urgent", "urgent"},
+		{"urgent", "urgent"},
+		{"a:b", "a:b"},
+		{"multi:a,b", "multi:a,b"},
+	}
+	for _, tt := range tests {
+		got, err := ParseHashtag(tt.s)
+		if err != nil {
+			t.Errorf("%s: %v", tt.s, err)
+			continue
+		}
+		if want := Must(Parse(tt.want)); !got.Equal(want) {
+			t.Errorf("%s: got %v, want %v", tt.s, got, want)
+		}
+	}
+}
+
+func TestParseHashtagInvalid(t *testing.T) {
+	for _, s := range []string{"#a:b", "#", "#urgent:"} {
+		if _, err := ParseHashtag(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	single := Must(NewSingleValue("path", "{{.Dir}}/file"))
+	got, err := single.Expand(map[string]string{"Dir": "/tmp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Must(Parse("path:/tmp/file")); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	multi := Must(NewMultiValue("hosts", "{{.Name}}.eu", "{{.Name}}.us"))
+	got, err = multi.Expand(struct{ Name string }{"app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Must(Parse("hosts:app.eu,app.us")); !got.Equal(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestExpandMalformedTemplate(t *testing.T) {
+	tag := Must(NewSingleValue("path", "{{.Dir"))
+	if _, err := tag.Expand(map[string]string{"Dir": "/tmp"}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestAppendTo(t *testing.T) {
+	for _, s := range []string{"label", "single:value", "multi:value1,value2"} {
+		tag := Must(Parse(s))
+		if got, want := string(tag.AppendTo(nil)), tag.String(); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	b := []byte("prefix ")
+	if got, want := string(Must(Parse("label")).AppendTo(b)), "prefix label"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func benchmarkTags() []Tag {
+	tags := make([]Tag, 0, 1000)
+	for i := 0; i < cap(tags); i++ {
+		tags = append(tags, Must(NewMultiValue("name"+strconv.Itoa(i), "value1", "value2")))
+	}
+	return tags
+}
+
+func BenchmarkString(b *testing.B) {
+	tags := benchmarkTags()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var s []byte
+		for _, t := range tags {
+			s = append(s, t.String()...)
+		}
+	}
+}
+
+func BenchmarkAppendTo(b *testing.B) {
+	tags := benchmarkTags()
+	b.ReportAllocs()
+	b.ResetTimer()
+	var s []byte
+	for i := 0; i < b.N; i++ {
+		s = s[:0]
+		for _, t := range tags {
+			s = t.AppendTo(s)
+		}
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	tests := []struct {
+		s      string
+		strict bool
+	}{
+		{"k:", false},
+		{"k:a,", false},
+		{"k:,a", false},
+		{"  k ", false},
+		{"k: a", false},
+		{"", false},
+		{"k", true},
+		{"k:a,b", true},
+	}
+	for _, tt := range tests {
+		lenient, lenientErr := Parse(tt.s)
+		strict, strictErr := ParseStrict(tt.s)
+
+		if tt.s != "" && lenientErr != nil {
+			t.Errorf("Parse(%q): %v", tt.s, lenientErr)
+		}
+		if (strictErr == nil) != tt.strict {
+			t.Errorf("ParseStrict(%q): got error %v, want error %v", tt.s, strictErr, !tt.strict)
+		}
+		if strictErr == nil && !strict.Equal(lenient) {
+			t.Errorf("ParseStrict(%q) = %v, Parse = %v", tt.s, strict, lenient)
+		}
+	}
+}
+
+func TestSortValuesFunc(t *testing.T) {
+	byLength := func(a, b string) bool { return len(a) < len(b) }
+	byVersion := func(a, b string) bool {
+		partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+		for i := 0; i < len(partsA) && i < len(partsB); i++ {
+			numA, _ := strconv.Atoi(partsA[i])
+			numB, _ := strconv.Atoi(partsB[i])
+			if numA != numB {
+				return numA < numB
+			}
+		}
+		return len(partsA) < len(partsB)
+	}
+
+	tests := []struct {
+		name   string
+		values []string
+		less   func(a, b string) bool
+		want   []string
+	}{
+		{"length", []string{"ccc", "bb", "a", "dd", "e"}, byLength, []string{"a", "e", "bb", "dd", "ccc"}},
+		{"version", []string{"1.10.0", "1.2.0", "1.9.1", "0.9"}, byVersion, []string{"0.9", "1.2.0", "1.9.1", "1.10.0"}},
+		{"single", []string{"a"}, byLength, []string{"a"}},
+		{"label", nil, byLength, []string{}},
+	}
+	for _, tt := range tests {
+		tag := Tag{name: "tag", values: tt.values}
+		original := slices.Clone(tt.values)
+
+		got := tag.SortValuesFunc(tt.less)
+		if !slices.Equal(got.Values(), tt.want) || got.Name() != "tag" {
+			t.Errorf("%s: got %v, want %q", tt.name, got, tt.want)
+		}
+		if !slices.Equal(tag.values, original) {
+			t.Errorf("%s: tag changed to %v", tt.name, tag)
+		}
+	}
+}
+
+func TestSortAllValuesFunc(t *testing.T) {
+	group := NewAnonymousGroup(
+		Tag{name: "multi", values: []string{"ccc", "a", "bb"}},
+		Must(NewSingleValue("single", "value")),
+		Must(NewLabel("label")),
+	)
+	group.SortAllValuesFunc(func(a, b string) bool { return len(a) < len(b) })
+
+	if tag, _ := group.Get("multi"); !slices.Equal(tag.Values(), []string{"a", "bb", "ccc"}) {
+		t.Errorf("got %v", tag)
+	}
+	if got, want := group.Canonical(), "label\nmulti:a,bb,ccc\nsingle:value\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestValueSet(t *testing.T) {
+	tag := Must(NewMultiValue("multi", "a", "b"))
+	set := tag.ValueSet()
+
+	if len(set) != 2 {
+		t.Errorf("got %v, want a and b", set)
+	}
+	for _, v := range []string{"a", "b"} {
+		if _, ok := set[v]; !ok {
+			t.Errorf("%s missing in %v", v, set)
+		}
+	}
+
+	set["c"] = struct{}{}
+	delete(set, "a")
+	if !tag.Equal(Must(NewMultiValue("multi", "a", "b"))) {
+		t.Errorf("tag changed to %v", tag)
+	}
+
+	if set := Must(NewLabel("label")).ValueSet(); len(set) != 0 {
+		t.Errorf("got %v for a label", set)
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  Tag
+		want bool
+	}{
+		{"zero", Tag{}, true},
+		{"label", Must(NewLabel("label")), false},
+		{"single", Must(NewSingleValue("single", "value")), false},
+		{"multi", Must(NewMultiValue("multi", "value1", "value2")), false},
+	}
+	for _, tt := range tests {
+		if got := tt.tag.IsZero(); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithValuesReplaced(t *testing.T) {
+	tag := Must(NewMultiValue("multi", "a", "b"))
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{[]string{"c", "d", "c"}, "multi:c,d"},
+		{[]string{"c"}, "multi:c"},
+		{[]string{"", ""}, "multi"},
+		{nil, "multi"},
+	}
+	for _, tt := range tests {
+		got, err := tag.WithValuesReplaced(tt.values...)
+		if err != nil {
+			t.Errorf("%q: %v", tt.values, err)
+			continue
+		}
+		if want := Must(Parse(tt.want)); !got.Equal(want) {
+			t.Errorf("%q: got %v, want %v", tt.values, got, want)
+		}
+	}
+
+	if !tag.Equal(Must(NewMultiValue("multi", "a", "b"))) {
+		t.Errorf("tag changed to %v", tag)
+	}
+}
+
+// setCase sets the Case for the duration of the test.
+func setCase(t *testing.T, mode CaseMode) {
+	t.Helper()
+	previous := Case
+	Case = mode
+	t.Cleanup(func() { Case = previous })
+}
+
+func setValueValidator(t *testing.T, fn func(string) error) {
+	t.Helper()
+	previous := ValueValidator
+	ValueValidator = fn
+	t.Cleanup(func() { ValueValidator = previous })
+}
+
+func rejectSpaces(v string) error {
+	if strings.Contains(v, " ") {
+		return errors.New("contains a space")
+	}
+	return nil
+}
+
+func TestNewCase(t *testing.T) {
+	tests := []struct {
+		name string
+		mode CaseMode
+		want Tag
+	}{
+		{"none", CaseNone, Tag{name: "Env", values: []string{"Prod", "prod", "PROD"}}},
+		{"lower", CaseLower, Tag{name: "env", values: []string{"prod"}}},
+		{"upper", CaseUpper, Tag{name: "ENV", values: []string{"PROD"}}},
+	}
+	for _, tt := range tests {
+		setCase(t, tt.mode)
+		got, err := New("Env", "Prod", "prod", "PROD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCaseDefault(t *testing.T) {
+	if Case != CaseNone {
+		t.Errorf("got %v, want CaseNone", Case)
+	}
+}
+
+func TestValueMembership(t *testing.T) {
+	vocab := []string{"a", "b", "c", "d"}
+	tests := []struct {
+		tag  string
+		want []bool
+	}{
+		{"multi:a,c", []bool{true, false, true, false}},
+		{"single:d", []bool{false, false, false, true}},
+		{"label", []bool{false, false, false, false}},
+	}
+	for _, tt := range tests {
+		tag := Must(Parse(tt.tag))
+		got := tag.ValueMembership(vocab)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.tag, got, tt.want)
+		}
+		for i, v := range vocab {
+			if got[i] != tag.HasValues(v) {
+				t.Errorf("%s: %s is %v, HasValues is %v", tt.tag, v, got[i], tag.HasValues(v))
+			}
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tag1 := Tag{name: "multi", values: []string{"a", "b"}}
+	tag2 := Tag{name: "multi", values: []string{"b", "a"}}
+	if tag1.Key() != tag2.Key() {
+		t.Errorf("%s != %s", tag1.Key(), tag2.Key())
+	}
+
+	// Tags whose String representations collide.
+	different := []Tag{
+		{name: "multi", values: []string{"a,b"}},
+		{name: "multi", values: []string{"a", "b"}},
+		{name: "multi:a", values: []string{"b"}},
+		{name: "multi", values: []string{"a:b"}},
+		{name: "multi"},
+		{name: "other", values: []string{"a", "b"}},
+	}
+	keys := make(map[string]Tag)
+	for _, tag := range different {
+		if other, ok := keys[tag.Key()]; ok {
+			t.Errorf("%#v and %#v have the same key %s", tag, other, tag.Key())
+		}
+		keys[tag.Key()] = tag
+	}
+}
+
+func TestNewWithReport(t *testing.T) {
+	tag, report, err := NewWithReport("multi", "a", "", "b", "a", " ", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := Must(NewMultiValue("multi", "a", "b")); !tag.Equal(want) {
+		t.Errorf("got %v, want %v", tag, want)
+	}
+	if want := []string{"", " "}; !slices.Equal(report.DroppedEmpty, want) {
+		t.Errorf("got DroppedEmpty %q, want %q", report.DroppedEmpty, want)
+	}
+	if want := []string{"a", "a"}; !slices.Equal(report.DroppedDuplicate, want) {
+		t.Errorf("got DroppedDuplicate %q, want %q", report.DroppedDuplicate, want)
+	}
+}
+
+func TestNewWithReportNothingDropped(t *testing.T) {
+	_, report, err := NewWithReport("multi", "a", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.DroppedEmpty) != 0 || len(report.DroppedDuplicate) != 0 {
+		t.Errorf("got %+v, want an empty report", report)
+	}
+
+	if _, _, err := NewWithReport("", "a"); err == nil {
+		t.Error("expected an error for an empty name")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Tag
+	}{
+		{"label", Tag{name: "label"}},
+		{"a:b", Tag{name: "a", values: []string{"b"}}},
+		{"multi:a,b", Tag{name: "multi", values: []string{"a", "b"}}},
+		{"multi:a,b,a", Tag{name: "multi", values: []string{"a", "b"}}},
+		{"multi:a,,b,", Tag{name: "multi", values: []string{"a", "b"}}},
+		{"name:", Tag{name: "name"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.s)
+		if err != nil {
+			t.Errorf("%s: %v", tt.s, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.s, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", ":value", " :value"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestHasAllValues(t *testing.T) {
+	tag := Must(NewMultiValue("multi", "a", "b", "c"))
+	tests := []struct {
+		values []string
+		all    bool
+		any    bool
+	}{
+		{[]string{"a"}, true, true},
+		{[]string{"c", "a"}, true, true},
+		{[]string{"a", "d"}, false, true},
+		{[]string{"d"}, false, false},
+		{nil, true, false},
+	}
+	for _, tt := range tests {
+		if got := tag.HasAllValues(tt.values...); got != tt.all {
+			t.Errorf("HasAllValues(%q): got %v, want %v", tt.values, got, tt.all)
+		}
+		if got := tag.HasValues(tt.values...); got != tt.any {
+			t.Errorf("HasValues(%q): got %v, want %v", tt.values, got, tt.any)
+		}
+	}
+}
+
+// collectPairs returns the pairs yielded by the seq as "name=value" strings.
+func collectPairs(seq iter.Seq2[string, string]) []string {
+	var pairs []string
+	for name, value := range seq {
+		pairs = append(pairs, name+"="+value)
+	}
+	return pairs
+}
+
+func TestPairs(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{"label", []string{"label="}},
+		{"single:value", []string{"single=value"}},
+		{"multi:a,b,c", []string{"multi=a", "multi=b", "multi=c"}},
+	}
+	for _, tt := range tests {
+		got := collectPairs(Must(Parse(tt.tag)).Pairs())
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestPairsBreak(t *testing.T) {
+	count := 0
+	for range Must(Parse("multi:a,b,c")).Pairs() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("got %d pairs, want 1", count)
+	}
+}
+
+func TestParseExpectMulti(t *testing.T) {
+	if _, err := ParseExpectMulti("k:a,a"); err == nil {
+		t.Error("k:a,a: expected an error")
+	}
+	for _, s := range []string{"k:a", "k", "k:a,"} {
+		if _, err := ParseExpectMulti(s); err == nil {
+			t.Errorf("%s: expected an error", s)
+		}
+	}
+
+	tag, err := ParseExpectMulti("k:a,b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tag.IsMultiValue() || !tag.Equal(Must(Parse("k:a,b"))) {
+		t.Errorf("got %v, want k:a,b", tag)
+	}
+}
+
+func TestNewSingleValues(t *testing.T) {
+	tags := NewSingleValues("multi", "a", "", "b", " ")
+
+	if len(tags) != 2 {
+		t.Fatalf("got %d tags, want 2", len(tags))
+	}
+	for i, value := range []string{"a", "b"} {
+		if want := Must(NewSingleValue("multi", value)); !tags[i].Equal(want) {
+			t.Errorf("got %v, want %v", tags[i], want)
+		}
+	}
+
+	if tags := NewSingleValues("", "a"); len(tags) != 0 {
+		t.Errorf("got %v for an empty name", tags)
+	}
+}
+
+func TestParseValuesWithColons(t *testing.T) {
+	tag, err := Parse("url:http://x,http://y")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tag.Name() != "url" || !tag.HasAllValues("http://x", "http://y") || len(tag.Values()) != 2 {
+		t.Errorf("got %#v, want url with http://x and http://y", tag)
+	}
+	if reparsed := Must(Parse(tag.String())); !reparsed.Equal(tag) {
+		t.Errorf("got %v, want %v", reparsed, tag)
+	}
+}
+
+func TestValuesContaining(t *testing.T) {
+	tests := []struct {
+		tag, substr string
+		want        []string
+	}{
+		{"multi:prod-eu,prod-us,dev-eu", "prod", []string{"prod-eu", "prod-us"}},
+		{"multi:prod-eu,prod-us,dev-eu", "stage", []string{}},
+		{"label", "prod", []string{}},
+	}
+	for _, tt := range tests {
+		got := Must(Parse(tt.tag)).ValuesContaining(tt.substr)
+		slices.Sort(got)
+		if got == nil || !slices.Equal(got, tt.want) {
+			t.Errorf("%s %s: got %q, want %q", tt.tag, tt.substr, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Sorted in the expected order.
+	tags := []Tag{
+		{name: "a"},
+		{name: "a", values: []string{"z"}},
+		{name: "a", values: []string{"a", "b"}},
+		{name: "a", values: []string{"a", "c"}},
+		{name: "b"},
+		{name: "b", values: []string{"a"}},
+	}
+	for i, tag1 := range tags {
+		for j, tag2 := range tags {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if got := tag1.Compare(tag2); got != want {
+				t.Errorf("%v vs %v: got %d, want %d", tag1, tag2, got, want)
+			}
+			if (tag1.Compare(tag2) == 0) != tag1.Equal(tag2) {
+				t.Errorf("%v vs %v: Compare and Equal disagree", tag1, tag2)
+			}
+		}
+	}
+
+	tag1 := Tag{name: "a", values: []string{"b", "a"}}
+	tag2 := Tag{name: "a", values: []string{"a", "b"}}
+	if tag1.Compare(tag2) != 0 || !tag1.Equal(tag2) {
+		t.Errorf("%v and %v are not equal", tag1, tag2)
+	}
+}
+
+func TestTrimValues(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  Tag
+		want Tag
+	}{
+		{"duplicates", Tag{name: "multi", values: []string{" a ", "a", "b "}}, Tag{name: "multi", values: []string{"a", "b"}}},
+		{"empty", Tag{name: "multi", values: []string{" ", "a"}}, Tag{name: "multi", values: []string{"a"}}},
+		{"all empty", Tag{name: "multi", values: []string{" ", "\t"}}, Tag{name: "multi"}},
+		{"label", Tag{name: "label"}, Tag{name: "label"}},
+	}
+	for _, tt := range tests {
+		if got := tt.tag.TrimValues(); !got.Equal(tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseVerbose(t *testing.T) {
+	tests := []struct {
+		tag        string
+		emptyValue bool
+	}{
+		{"label", false},
+		{"label:", true},
+		{"single:value", false},
+		{"multi:a,b", false},
+	}
+	for _, tt := range tests {
+		tag := Must(ParseVerbose(tt.tag))
+		if tag.IsEmptyValue() != tt.emptyValue {
+			t.Errorf("%s: got IsEmptyValue %v, want %v", tt.tag, tag.IsEmptyValue(), tt.emptyValue)
+		}
+		if got := tag.StringVerbose(); strings.HasSuffix(got, nameValueSeparator) != tt.emptyValue {
+			t.Errorf("%s: got %q", tt.tag, got)
+		}
+		if got := Must(ParseVerbose(tag.StringVerbose())); got.IsEmptyValue() != tt.emptyValue || !got.Equal(tag) {
+			t.Errorf("%s: round-trip got %#v, want %#v", tt.tag, got, tag)
+		}
+	}
+
+	if got := Must(Parse("label:")); got.IsEmptyValue() {
+		t.Error("Parse kept the empty value")
+	}
+}
+
+func TestEmptyValueKept(t *testing.T) {
+	tag := Must(ParseVerbose("label:"))
+	tests := []struct {
+		name string
+		tag  Tag
+	}{
+		{"sorted", tag.sorted()},
+		{"SortValuesFunc", tag.SortValuesFunc(func(a, b string) bool { return a < b })},
+	}
+	for _, tt := range tests {
+		if !tt.tag.IsEmptyValue() {
+			t.Errorf("%s: the empty value was lost", tt.name)
+		}
+	}
+}
+
+func TestValueValidator(t *testing.T) {
+	setValueValidator(t, rejectSpaces)
+
+	tests := []struct {
+		values []string
+		valid  bool
+	}{
+		{[]string{"value"}, true},
+		{[]string{"value", " "}, true},
+		{[]string{"value", "two words"}, false},
+	}
+	for _, tt := range tests {
+		_, err := New("name", tt.values...)
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: got %v, want valid %v", tt.values, err, tt.valid)
+		}
+	}
+
+	if _, err := Parse("name:a b"); err == nil {
+		t.Error("Parse accepted an invalid value")
+	}
+}
+
+func TestRegexpValueValidator(t *testing.T) {
+	setValueValidator(t, RegexpValueValidator(regexp.MustCompile(`^[a-z]+$`)))
+
+	if _, err := New("name", "value", "other"); err != nil {
+		t.Error(err)
+	}
+	if _, err := New("name", "value", "Value1"); err == nil {
+		t.Error("expected an error for a value not matching the regexp")
+	}
+}
+
+func TestTrimValuesInvalid(t *testing.T) {
+	setValueValidator(t, func(v string) error {
+		if v == "invalid" {
+			return errors.New("invalid")
+		}
+		return nil
+	})
+
+	tag := Tag{name: "multi", values: []string{" invalid ", "a"}}
+	if got := tag.TrimValues(); !got.Equal(tag) {
+		t.Errorf("got %#v, want %#v", got, tag)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name           string
+		tag, other     string
+		added, removed []string
+	}{
+		{"added", "env:prod", "env:prod,stage", []string{"stage"}, nil},
+		{"removed", "env:prod,stage", "env:stage", nil, []string{"prod"}},
+		{"changed", "env:prod", "env:dev", []string{"dev"}, []string{"prod"}},
+		{"no change", "env:prod,stage", "env:stage,prod", nil, nil},
+		{"labels", "env", "env", nil, nil},
+		{"name mismatch", "env:prod", "other:dev", nil, nil},
+	}
+	for _, tt := range tests {
+		added, removed := Must(Parse(tt.tag)).Diff(Must(Parse(tt.other)))
+		if !slices.Equal(added, tt.added) || !slices.Equal(removed, tt.removed) {
+			t.Errorf("%s: got %q %q, want %q %q", tt.name, added, removed, tt.added, tt.removed)
+		}
+	}
+}
+
+func TestParseFlag(t *testing.T) {
+	tests := []struct {
+		flag    string
+		name    string
+		enabled bool
+	}{
+		{"feature", "feature", true},
+		{"feature!", "feature", false},
+	}
+	for _, tt := range tests {
+		name, enabled, tag, err := ParseFlag(tt.flag)
+		if err != nil {
+			t.Errorf("%s: %v", tt.flag, err)
+			continue
+		}
+		if name != tt.name || enabled != tt.enabled {
+			t.Errorf("%s: got %s %v, want %s %v", tt.flag, name, enabled, tt.name, tt.enabled)
+		}
+		if !tag.Equal(Must(NewLabel(tt.name))) {
+			t.Errorf("%s: got %#v, want the label %s", tt.flag, tag, tt.name)
+		}
+	}
+
+	for _, flag := range []string{"feature:x!", "feature:x", "!", ""} {
+		if _, _, _, err := ParseFlag(flag); err == nil {
+			t.Errorf("%q: expected an error", flag)
+		}
+	}
+}
+
+func TestWithNamespace(t *testing.T) {
+	tests := []struct {
+		tag  string
+		ns   string
+		want string
+	}{
+		{"env:prod", "app", "app.env:prod"},
+		{"app.env:prod", "org", "org.app.env:prod"},
+		{"multi:a,b", "app", "app.multi:a,b"},
+		{"label", "app", "app.label"},
+		{"env:prod", "", "env:prod"},
+	}
+	for _, tt := range tests {
+		got := Must(Must(Parse(tt.tag)).WithNamespace(tt.ns))
+		if want := Must(Parse(tt.want)); !got.Equal(want) {
+			t.Errorf("%s in %q: got %v, want %v", tt.tag, tt.ns, got, want)
+		}
+	}
+}
+
+func TestWithNamespaceSeparator(t *testing.T) {
+	previous := NamespaceSeparator
+	NamespaceSeparator = "/"
+	t.Cleanup(func() { NamespaceSeparator = previous })
+
+	got := Must(Must(Parse("env:prod")).WithNamespace("app"))
+	if got.Name() != "app/env" {
+		t.Errorf("got %s, want app/env", got.Name())
+	}
+}
+
+func TestRepairValue(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  Tag
+		want Tag
+	}{
+		{"joined", Tag{name: "multi", values: []string{"a,b,c"}}, Tag{name: "multi", values: []string{"a", "b", "c"}}},
+		{"repeating", Tag{name: "multi", values: []string{"a,b", "b,,c"}}, Tag{name: "multi", values: []string{"a", "b", "c"}}},
+		{"single", Tag{name: "single", values: []string{"value"}}, Tag{name: "single", values: []string{"value"}}},
+		{"multi", Tag{name: "multi", values: []string{"a", "b"}}, Tag{name: "multi", values: []string{"a", "b"}}},
+		{"label", Tag{name: "label"}, Tag{name: "label"}},
+	}
+	for _, tt := range tests {
+		got := RepairValue(tt.tag)
+		if !got.Equal(tt.want) || len(got.Values()) != len(tt.want.Values()) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNewEnum(t *testing.T) {
+	allowed := []string{"low", "medium", "high"}
+
+	tag, err := NewEnum("severity", allowed, "high")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tag.Equal(Must(NewSingleValue("severity", "high"))) {
+		t.Errorf("got %v, want severity:high", tag)
+	}
+
+	for _, value := range []string{"critical", "", "High"} {
+		if _, err := NewEnum("severity", allowed, value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+	if _, err := NewEnum("", allowed, "high"); err == nil {
+		t.Error("expected an error for an empty name")
+	}
+}
+
+func TestTagKinds(t *testing.T) {
+	tests := []struct {
+		values                  []string
+		label, single, multiple bool
+	}{
+		{nil, true, false, false},
+		{[]string{"a"}, false, true, false},
+		{[]string{"a", "b"}, false, false, true},
+		{[]string{"a", "b", "c"}, false, false, true},
+	}
+	for _, tt := range tests {
+		tag := Must(New("name", tt.values...))
+		if got := tag.IsLabel(); got != tt.label {
+			t.Errorf("%d values: got IsLabel %v, want %v", len(tt.values), got, tt.label)
+		}
+		if got := tag.IsSingleValue(); got != tt.single {
+			t.Errorf("%d values: got IsSingleValue %v, want %v", len(tt.values), got, tt.single)
+		}
+		if got := tag.IsMultiValue(); got != tt.multiple {
+			t.Errorf("%d values: got IsMultiValue %v, want %v", len(tt.values), got, tt.multiple)
+		}
+	}
+}
+
+func TestValueParts(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  Tag
+		want [][]string
+	}{
+		{"nested", Tag{name: "matrix", values: []string{"a;b", "c;d;e"}}, [][]string{{"a", "b"}, {"c", "d", "e"}}},
+		{"plain", Tag{name: "matrix", values: []string{"a;b", "c"}}, [][]string{{"a", "b"}, {"c"}}},
+		{"label", Tag{name: "label"}, [][]string{}},
+	}
+	for _, tt := range tests {
+		got := tt.tag.ValueParts(";")
+		if !slices.EqualFunc(got, tt.want, slices.Equal[string]) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	parts := Must(Parse("matrix:a;b,c;d")).ValueParts(";")
+	if len(parts) != 2 || len(parts[0]) != 2 || len(parts[1]) != 2 {
+		t.Errorf("got %q, want two values with two parts", parts)
+	}
+}
+
+func TestNewMultiValueBounded(t *testing.T) {
+	tag, err := NewMultiValueBounded("multi", 2, 3, "a", "b", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := Must(Parse("multi:a,b,c")); !tag.Equal(want) {
+		t.Errorf("got %v, want %v", tag, want)
+	}
+
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{"below min", []string{"a", "b", "a"}, "invalid number of unique values: 2 (allowed: 3-4)"},
+		{"above max", []string{"a", "b", "c", "d", "e"}, "invalid number of unique values: 5 (allowed: 3-4)"},
+	}
+	for _, tt := range tests {
+		_, err := NewMultiValueBounded("multi", 3, 4, tt.values...)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%s: got %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := NewMultiValueBounded("multi", 0, 5, "a"); err == nil {
+		t.Error("expected an error for a single value")
+	}
+
+	_, err = NewMultiValueBounded("multi", 5, 2, "a", "b", "c")
+	if want := "invalid bounds: 5-2 (minimum greater than maximum)"; err == nil || err.Error() != want {
+		t.Errorf("got %v, want %q", err, want)
+	}
+}
+
+func TestSortedUniqueValues(t *testing.T) {
+	tag := Tag{name: "multi", values: []string{"c", "a", "b", "a"}}
+
+	got := tag.SortedUniqueValues()
+	if want := []string{"a", "b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got[0] = "changed"
+	if want := []string{"c", "a", "b", "a"}; !slices.Equal(tag.Values(), want) {
+		t.Errorf("tag changed to %q", tag.Values())
+	}
+
+	created := Must(NewMultiValue("multi", "c", "a", "b"))
+	values := slices.Clone(created.Values())
+	slices.Sort(values)
+	if !slices.Equal(created.SortedUniqueValues(), values) {
+		t.Errorf("got %q, want %q", created.SortedUniqueValues(), values)
+	}
+
+	if got := Must(NewLabel("label")).SortedUniqueValues(); len(got) != 0 {
+		t.Errorf("got %q for a label", got)
+	}
+}
@@ -0,0 +1,109 @@
+package tags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TokenKind is the kind of a [Token].
+type TokenKind int
+
+const (
+	// TokenName is a tag name.
+	TokenName TokenKind = iota
+	// TokenSeparator is a name-value or values separator.
+	TokenSeparator
+	// TokenValue is a tag value.
+	TokenValue
+)
+
+// String returns the name of the token kind.
+func (k TokenKind) String() string {
+	switch k {
+	case TokenName:
+		return "name"
+	case TokenSeparator:
+		return "separator"
+	case TokenValue:
+		return "value"
+	default:
+		return fmt.Sprintf("TokenKind(%d)", int(k))
+	}
+}
+
+// Token is a part of a string representation of a tag.
+type Token struct {
+	Kind TokenKind
+	// Text is the token text, i.e. s[Start:End].
+	Text string
+	// Start is the byte offset of the token start.
+	Start int
+	// End is the byte offset after the token end.
+	End int
+}
+
+// SyntaxError is returned by the [Tokenize] function for malformed input.
+type SyntaxError struct {
+	// Offset is the byte offset where the error occurred.
+	Offset int
+	Msg    string
+}
+
+// Error returns the error message with the offset.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Msg)
+}
+
+// Tokenize splits a string representation of a tag in the name[:value,...]
+// format into tokens with their positions. It's useful for linting or syntax
+// highlighting.
+//
+// Example:
+//
+//	Tokenize("multi:a,b") -> name "multi" [0,5), separator ":" [5,6),
+//	    value "a" [6,7), separator "," [7,8), value "b" [8,9)
+//
+// The values can contain ':', see the [Parse] function. Empty (or whitespace
+// only) names and empty values are reported as a [*SyntaxError].
+func Tokenize(s string) ([]Token, error) {
+	end := strings.Index(s, nameValueSeparator)
+	if end == -1 {
+		end = len(s)
+	}
+	if strings.TrimSpace(s[:end]) == "" {
+		return nil, &SyntaxError{Offset: 0, Msg: "name required"}
+	}
+
+	tokens := []Token{{Kind: TokenName, Text: s[:end], Start: 0, End: end}}
+	if end == len(s) {
+		return tokens, nil
+	}
+
+	separator := nameValueSeparator
+	for start := end; start < len(s); {
+		tokens = append(tokens, Token{
+			Kind:  TokenSeparator,
+			Text:  separator,
+			Start: start,
+			End:   start + len(separator),
+		})
+		start += len(separator)
+
+		end = strings.Index(s[start:], valuesSeparator)
+		if end == -1 {
+			end = len(s)
+		} else {
+			end += start
+		}
+
+		value := s[start:end]
+		if value == "" {
+			return nil, &SyntaxError{Offset: start, Msg: "value required"}
+		}
+
+		tokens = append(tokens, Token{Kind: TokenValue, Text: value, Start: start, End: end})
+		start = end
+		separator = valuesSeparator
+	}
+	return tokens, nil
+}
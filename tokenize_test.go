@@ -0,0 +1,72 @@
+package tags
+
+import (
+	"errors"
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := Tokenize("multi:a,b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Token{
+		{Kind: TokenName, Text: "multi", Start: 0, End: 5},
+		{Kind: TokenSeparator, Text: ":", Start: 5, End: 6},
+		{Kind: TokenValue, Text: "a", Start: 6, End: 7},
+		{Kind: TokenSeparator, Text: ",", Start: 7, End: 8},
+		{Kind: TokenValue, Text: "b", Start: 8, End: 9},
+	}
+	if !slices.Equal(tokens, want) {
+		t.Errorf("got %v, want %v", tokens, want)
+	}
+}
+
+func TestTokenizeLabel(t *testing.T) {
+	tokens, err := Tokenize("label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Token{{Kind: TokenName, Text: "label", Start: 0, End: 5}}; !slices.Equal(tokens, want) {
+		t.Errorf("got %v, want %v", tokens, want)
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	tests := []struct {
+		s      string
+		offset int
+	}{
+		{":a", 0},
+		{" :a", 0},
+		{"\t", 0},
+		{"", 0},
+		{"multi:", 6},
+		{"multi:a,", 8},
+		{"multi:a,,b", 8},
+	}
+	for _, tt := range tests {
+		_, err := Tokenize(tt.s)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: got %v, want a SyntaxError", tt.s, err)
+			continue
+		}
+		if syntaxErr.Offset != tt.offset {
+			t.Errorf("%q: got offset %d, want %d", tt.s, syntaxErr.Offset, tt.offset)
+		}
+	}
+}
+
+func TestTokenizeNamesLikeParse(t *testing.T) {
+	for _, s := range []string{"", " ", " :a", "\t:a,b", "name", " name :a"} {
+		_, tokenizeErr := Tokenize(s)
+		_, parseErr := Parse(s)
+		if (tokenizeErr == nil) != (parseErr == nil) {
+			t.Errorf("%q: Tokenize returned %v, Parse returned %v", s, tokenizeErr, parseErr)
+		}
+	}
+}
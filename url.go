@@ -0,0 +1,40 @@
+package tags
+
+import "net/url"
+
+// FromURLValues creates a group with the name from the URL values.
+//
+// Each key becomes a tag with the key values, see the [New] function docs.
+// A key without values (or with just an empty value) becomes a label. Keys
+// that are not valid tag names are skipped.
+//
+// It panics if the name is empty.
+func FromURLValues(name string, v url.Values) TagGroup {
+	group := Must(NewGroup(name))
+	for key, values := range v {
+		tag, err := New(key, values...)
+		if err != nil {
+			continue
+		}
+		group.Add(tag)
+	}
+	return group
+}
+
+// URLValues returns the group tags as URL values.
+//
+// Each tag becomes a key with the tag values. A label becomes a key with just
+// an empty value.
+//
+// This method is the reverse of the [FromURLValues] function.
+func (g *TagGroup) URLValues() url.Values {
+	v := make(url.Values, len(g.tags))
+	for _, t := range g.tags {
+		if t.IsLabel() {
+			v[t.name] = []string{""}
+		} else {
+			v[t.name] = t.SortedUniqueValues()
+		}
+	}
+	return v
+}
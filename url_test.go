@@ -0,0 +1,31 @@
+package tags
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestURLValuesRoundTrip(t *testing.T) {
+	query := "label=&multi=a&multi=b&path=%2Ftmp%2Fa+b&single=value"
+	v, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group := FromURLValues("group", v)
+	want := "label\nmulti:a,b\npath:/tmp/a b\nsingle:value\n"
+	if got := group.Canonical(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if got := group.URLValues().Encode(); got != query {
+		t.Errorf("got %q, want %q", got, query)
+	}
+}
+
+func TestFromURLValuesSkipsInvalidKeys(t *testing.T) {
+	group := FromURLValues("group", url.Values{"": {"value"}, "label": nil})
+	if got, want := group.Canonical(), "label\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}